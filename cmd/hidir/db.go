@@ -0,0 +1,189 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/pflag"
+
+	"HiDir/internal/report"
+)
+
+// dbUsage db子命令的用法
+const dbUsage = `Usage:
+  hidir db list <database>
+  hidir db export <database> <scan-id> [--format FORMAT] [-o FILE]
+  hidir db diff <database> <old-scan-id> <new-scan-id> [--format FORMAT] [-o FILE]
+`
+
+// runDB 执行db子命令，管理sqlite格式保存的扫描结果
+func runDB(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing command\n%s", dbUsage)
+	}
+
+	switch args[0] {
+	case "list":
+		return runDBList(args[1:])
+	case "export":
+		return runDBExport(args[1:])
+	case "diff":
+		return runDBDiff(args[1:])
+	case "-h", "--help", "help":
+		fmt.Print(dbUsage)
+		return nil
+	default:
+		return fmt.Errorf("unknown command %q\n%s", args[0], dbUsage)
+	}
+}
+
+// runDBList 列出数据库中的所有扫描
+func runDBList(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("expected a database path\n%s", dbUsage)
+	}
+
+	db, err := openExistingDatabase(args[0])
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	scans, err := db.Scans()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%-6s %-19s  %-19s  %8s  %s\n", "ID", "Started", "Finished", "Findings", "Targets")
+	for _, scan := range scans {
+		finished := "interrupted"
+		if !scan.EndTime.IsZero() {
+			finished = scan.EndTime.Local().Format("2006-01-02 15:04:05")
+		}
+		fmt.Printf("%-6d %-19s  %-19s  %8d  %s\n", scan.ID, scan.StartTime.Local().Format("2006-01-02 15:04:05"),
+			finished, scan.Findings, strings.Join(scan.Targets, ", "))
+	}
+	return nil
+}
+
+// runDBExport 将一次扫描导出为报告
+func runDBExport(args []string) error {
+	flags, output, format := reportFlags("db export")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 2 {
+		return fmt.Errorf("expected a database path and a scan id\n%s", dbUsage)
+	}
+
+	scanID, err := parseScanID(flags.Arg(1))
+	if err != nil {
+		return err
+	}
+
+	db, err := openExistingDatabase(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	scan, err := db.LoadScan(scanID)
+	if err != nil {
+		return err
+	}
+	return writeReport(scan, *output, *format)
+}
+
+// runDBDiff 比较数据库中的两次扫描
+func runDBDiff(args []string) error {
+	flags, output, format := reportFlags("db diff")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 3 {
+		return fmt.Errorf("expected a database path and two scan ids\n%s", dbUsage)
+	}
+
+	oldID, err := parseScanID(flags.Arg(1))
+	if err != nil {
+		return err
+	}
+	newID, err := parseScanID(flags.Arg(2))
+	if err != nil {
+		return err
+	}
+
+	db, err := openExistingDatabase(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	old, err := db.LoadScan(oldID)
+	if err != nil {
+		return err
+	}
+	current, err := db.LoadScan(newID)
+	if err != nil {
+		return err
+	}
+	return writeReport(report.Diff(old, current, fmt.Sprintf("scan %d", oldID)), *output, *format)
+}
+
+// parseScanID 解析扫描ID
+func parseScanID(value string) (int64, error) {
+	scanID, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid scan id %q", value)
+	}
+	return scanID, nil
+}
+
+// reportFlags 创建带有 -o 和 --format 参数的子命令参数集
+func reportFlags(name string) (*pflag.FlagSet, *string, *string) {
+	flags := pflag.NewFlagSet(name, pflag.ContinueOnError)
+	output := flags.StringP("output", "o", "", "Output file (default: stdout)")
+	format := flags.String("format", "", "Report format (default: detected from the output file, plain for stdout)")
+	return flags, output, format
+}
+
+// writeReport 将报告写入文件，output为空时写入标准输出
+func writeReport(r *report.Report, output, format string) error {
+	if format == "" {
+		format = report.DetectFormat(output)
+	}
+	writer, err := report.NewWriter(format)
+	if err != nil {
+		return err
+	}
+
+	if output == "" {
+		return report.Write(writer, os.Stdout, r)
+	}
+
+	file, err := report.CreateFile(output, format, r.Info)
+	if err != nil {
+		return err
+	}
+	for _, result := range r.Results {
+		if err := file.Add(result); err != nil {
+			file.Close(r.Info.EndTime)
+			return err
+		}
+	}
+	if err := file.Close(r.Info.EndTime); err != nil {
+		return err
+	}
+	fmt.Printf("Report saved to %s\n", output)
+	return nil
+}
+
+// openExistingDatabase 打开已存在的数据库，避免路径错误时创建空数据库
+func openExistingDatabase(path string) (*report.Database, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("database %s: %w", path, err)
+	}
+	return report.OpenDatabase(path)
+}
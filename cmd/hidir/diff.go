@@ -0,0 +1,35 @@
+package main
+
+import (
+	"fmt"
+
+	"HiDir/internal/report"
+)
+
+// diffUsage diff子命令的用法
+const diffUsage = `Usage:
+  hidir diff <old-report> <new-report> [--format FORMAT] [-o FILE]
+
+Reports must be json or jsonl. Use "hidir db diff" to compare scans stored in a sqlite database.
+`
+
+// runDiff 执行diff子命令，比较两份报告
+func runDiff(args []string) error {
+	flags, output, format := reportFlags("diff")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 2 {
+		return fmt.Errorf("expected two reports\n%s", diffUsage)
+	}
+
+	old, err := report.LoadFile(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	current, err := report.LoadFile(flags.Arg(1))
+	if err != nil {
+		return err
+	}
+	return writeReport(report.Diff(old, current, flags.Arg(0)), *output, *format)
+}
@@ -1,28 +1,50 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
+	"github.com/spf13/pflag"
+
+	"HiDir/internal/common"
 	"HiDir/internal/core"
 	"HiDir/internal/parse"
 )
 
-const VERSION = "1.0.0"
+// 子命令
+var commands = map[string]func(args []string) error{
+	"db":   runDB,
+	"diff": runDiff,
+}
 
 func main() {
-	// 打印版本信息
-	fmt.Printf("HiDir v%s\n\n", VERSION)
+	// 子命令，输出可能被重定向，不打印版本信息
+	if len(os.Args) > 1 {
+		if command, ok := commands[os.Args[1]]; ok {
+			// 错误统一输出到标准错误，-h 只打印用法
+			if err := command(os.Args[2:]); err != nil && !errors.Is(err, pflag.ErrHelp) {
+				fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
 
 	// 解析命令行参数
 	opts := parse.ParseArguments()
 
+	// 打印版本信息，安静模式下只输出结果
+	if !opts.Quiet {
+		fmt.Printf("HiDir v%s\n\n", common.VERSION)
+	}
+
 	// 初始化控制器
 	controller := core.NewController(opts)
 
 	// 设置控制器
 	if err := controller.Setup(); err != nil {
-		fmt.Printf("Error: %s\n", err)
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
 		os.Exit(1)
 	}
 
@@ -1,5 +1,8 @@
 package common
 
+// 版本号
+const VERSION = "1.0.0"
+
 // 认证类型
 var AUTHENTICATION_TYPES = []string{"basic", "bearer"}
 
@@ -10,7 +13,7 @@ var COMMON_EXTENSIONS = []string{"php", "html", "htm", "asp", "aspx", "jsp", "js
 var DEFAULT_TOR_PROXIES = []string{"socks5://127.0.0.1:9050"}
 
 // 输出格式
-var OUTPUT_FORMATS = []string{"simple", "plain", "json", "xml", "md", "csv", "html"}
+var OUTPUT_FORMATS = []string{"simple", "plain", "json", "jsonl", "xml", "md", "csv", "html", "sqlite"}
 
 // 默认HTTP头
 var DEFAULT_HEADERS = map[string]string{
@@ -34,12 +37,24 @@ var DEFAULT_TEST_PREFIXES = []string{"_", "."}
 // 默认测试后缀
 var DEFAULT_TEST_SUFFIXES = []string{"_", "."}
 
+// 默认占位符，出现在请求中时用单词替换，而不是追加到路径
+const FUZZ_KEYWORD = "FUZZ"
+
 // 通配符测试标记
 const WILDCARD_TEST_POINT_MARKER = "_dirsearch_"
 
 // 最大连续请求错误数
 const MAX_CONSECUTIVE_REQUEST_ERRORS = 5
 
+// 跟随重定向的最大次数
+const MAX_REDIRECTS = 10
+
+// 会话文件默认保存目录
+const SESSIONS_DIRECTORY = "sessions"
+
+// 会话自动保存间隔（秒）
+const SESSION_SAVE_INTERVAL = 10
+
 // 暂停等待超时
 const PAUSING_WAIT_TIMEOUT = 30
 
@@ -48,3 +63,15 @@ const UNKNOWN = "unknown"
 
 // 扩展名识别正则
 const EXTENSION_RECOGNITION_REGEX = `\.([a-zA-Z0-9]+)$`
+
+// 重定向中反射路径的标记
+const REFLECTED_PATH_MARKER = "__REFLECTED_PATH__"
+
+// 默认相似度阈值
+const DEFAULT_SIMILARITY_THRESHOLD = 0.95
+
+// 字典中的扩展名占位符
+const EXTENSION_TAG = "%ext%"
+
+// 覆盖扩展名时保留的扩展名
+var EXCLUDE_OVERWRITE_EXTENSIONS = []string{"log", "json", "xml", "jpg", "jpeg", "png", "gif", "bmp", "ico", "svg", "webp", "mp3", "mp4", "avi", "zip", "rar", "7z", "tar", "gz", "pdf", "doc", "docx", "xls", "xlsx", "ppt", "pptx", "txt", "csv", "css", "js"}
@@ -0,0 +1,31 @@
+package connection
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"html"
+	"regexp"
+	"strings"
+)
+
+// HTML标题
+var titleRegex = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+
+// computeMetrics 计算响应的统计信息：单词数、行数、标题、内容类型和内容哈希
+func (r *Response) computeMetrics() {
+	r.Words = len(strings.Fields(r.Content))
+	if r.Content != "" {
+		r.Lines = strings.Count(r.Content, "\n") + 1
+	}
+
+	if match := titleRegex.FindStringSubmatch(r.Content); match != nil {
+		r.Title = strings.Join(strings.Fields(html.UnescapeString(match[1])), " ")
+	}
+
+	if values := r.Headers["Content-Type"]; len(values) > 0 {
+		r.ContentType = strings.TrimSpace(strings.SplitN(values[0], ";", 2)[0])
+	}
+
+	sum := sha256.Sum256([]byte(r.Content))
+	r.BodyHash = hex.EncodeToString(sum[:])
+}
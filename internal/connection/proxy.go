@@ -0,0 +1,308 @@
+package connection
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 代理健康检查超时
+const proxyCheckTimeout = 3 * time.Second
+
+// 代理默认端口
+var proxyPorts = map[string]string{
+	"http":    "80",
+	"https":   "443",
+	"socks5":  "1080",
+	"socks5h": "1080",
+}
+
+// ErrNoProxies 代理池中的代理都已失效
+var ErrNoProxies = errors.New("no working proxies left")
+
+// proxyKey 在请求上下文中保存本次请求使用的代理
+type proxyKey struct{}
+
+// ProxyPool 代理池，按轮询或随机顺序为每个请求选择代理
+//
+// 通过代理的请求失败时在后台与代理握手检查代理是否可用，不可用的代理会被移出代理池。
+type ProxyPool struct {
+	mutex    sync.Mutex
+	proxies  []*url.URL
+	random   bool
+	next     int
+	suspects map[*url.URL]bool // 正在检查的代理，检查完成前尽量不选择
+	checks   sync.WaitGroup
+	onEvict  func(proxy *url.URL, err error)
+}
+
+// NewProxyPool 创建新的ProxyPool实例，random为true时随机选择代理，否则轮询
+func NewProxyPool(proxies []string, random bool) (*ProxyPool, error) {
+	pool := &ProxyPool{random: random, suspects: make(map[*url.URL]bool)}
+	for _, proxy := range proxies {
+		proxyURL, err := ParseProxy(proxy)
+		if err != nil {
+			return nil, err
+		}
+		pool.proxies = append(pool.proxies, proxyURL)
+	}
+	if len(pool.proxies) == 0 {
+		return nil, fmt.Errorf("no proxies given")
+	}
+
+	return pool, nil
+}
+
+// ParseProxy 解析代理URL，未指定协议时使用http
+func ParseProxy(proxy string) (*url.URL, error) {
+	proxy = strings.TrimSpace(proxy)
+	if !strings.Contains(proxy, "://") {
+		proxy = "http://" + proxy
+	}
+
+	proxyURL, err := url.Parse(proxy)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy %q: %w", proxy, err)
+	}
+	proxyURL.Scheme = strings.ToLower(proxyURL.Scheme)
+	if _, ok := proxyPorts[proxyURL.Scheme]; !ok {
+		return nil, fmt.Errorf("invalid proxy %q: unsupported scheme %s", proxy, proxyURL.Scheme)
+	}
+	if proxyURL.Hostname() == "" {
+		return nil, fmt.Errorf("invalid proxy %q: missing host", proxy)
+	}
+
+	return proxyURL, nil
+}
+
+// SetEvictCallback 设置代理被移出代理池时的回调
+func (p *ProxyPool) SetEvictCallback(callback func(proxy *url.URL, err error)) {
+	p.onEvict = callback
+}
+
+// Len 返回代理池中的代理数
+func (p *ProxyPool) Len() int {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return len(p.proxies)
+}
+
+// Next 选择下一个代理，跳过正在检查的代理，所有代理都在检查时照常选择
+func (p *ProxyPool) Next() (*url.URL, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if len(p.proxies) == 0 {
+		return nil, ErrNoProxies
+	}
+	for range p.proxies {
+		if proxy := p.pick(); !p.suspects[proxy] {
+			return proxy, nil
+		}
+	}
+	return p.pick(), nil
+}
+
+// pick 按轮询或随机顺序取出一个代理，调用时需持有锁
+func (p *ProxyPool) pick() *url.URL {
+	if p.random {
+		return p.proxies[rand.Intn(len(p.proxies))]
+	}
+
+	p.next %= len(p.proxies)
+	proxy := p.proxies[p.next]
+	p.next++
+	return proxy
+}
+
+// Fail 报告通过代理的请求失败，在后台检查代理，不阻塞发送请求的线程
+//
+// auth为请求使用的代理认证信息，target为失败请求的URL。
+// 同一个代理同时只进行一次检查，检查期间的失败报告会被忽略。
+func (p *ProxyPool) Fail(proxy *url.URL, auth *url.Userinfo, target string) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.suspects[proxy] {
+		return
+	}
+	p.suspects[proxy] = true
+	p.checks.Add(1)
+	go p.check(proxy, auth, target)
+}
+
+// check 检查代理是否可用，不可用时将其移出代理池
+func (p *ProxyPool) check(proxy *url.URL, auth *url.Userinfo, target string) {
+	defer p.checks.Done()
+
+	// 代理可用时，请求失败可能是目标的问题
+	err := checkProxy(proxy, auth, target)
+
+	p.mutex.Lock()
+	delete(p.suspects, proxy)
+	evicted := false
+	if err != nil {
+		for i, item := range p.proxies {
+			if item == proxy {
+				p.proxies = append(p.proxies[:i], p.proxies[i+1:]...)
+				evicted = true
+				break
+			}
+		}
+	}
+	p.mutex.Unlock()
+
+	if evicted && p.onEvict != nil {
+		p.onEvict(proxy, err)
+	}
+}
+
+// wait 等待正在进行的检查完成
+func (p *ProxyPool) wait() {
+	p.checks.Wait()
+}
+
+// checkProxy 与代理握手检查代理是否可用
+//
+// HTTP代理发送CONNECT请求，SOCKS5代理完成认证协商后断开，不发送CONNECT命令。
+// 代理按协议应答即视为可用，目标无法访问（如代理返回502）不会导致代理被移出；
+// 连接失败、应答不符合协议或认证被拒绝时视为不可用。
+func checkProxy(proxy *url.URL, auth *url.Userinfo, target string) error {
+	port := proxy.Port()
+	if port == "" {
+		port = proxyPorts[proxy.Scheme]
+	}
+
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(proxy.Hostname(), port), proxyCheckTimeout)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(proxyCheckTimeout))
+
+	if auth == nil {
+		auth = proxy.User
+	}
+	switch proxy.Scheme {
+	case "socks5", "socks5h":
+		return checkSOCKS5(conn, auth)
+	case "https":
+		// 与发送请求时一致，不验证代理的证书
+		conn = tls.Client(conn, &tls.Config{ServerName: proxy.Hostname(), InsecureSkipVerify: true})
+	}
+	return checkConnect(conn, auth, target)
+}
+
+// checkConnect 通过HTTP代理发送到目标主机的CONNECT请求，代理要求认证时返回错误
+func checkConnect(conn net.Conn, auth *url.Userinfo, target string) error {
+	address := "example.com:443"
+	if targetURL, err := url.Parse(target); err == nil && targetURL.Hostname() != "" {
+		port := targetURL.Port()
+		if port == "" {
+			port = proxyPorts[strings.ToLower(targetURL.Scheme)]
+		}
+		if port == "" {
+			port = "80"
+		}
+		address = net.JoinHostPort(targetURL.Hostname(), port)
+	}
+
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: address},
+		Host:   address,
+		Header: make(http.Header),
+	}
+	if auth != nil {
+		password, _ := auth.Password()
+		credentials := base64.StdEncoding.EncodeToString([]byte(auth.Username() + ":" + password))
+		req.Header.Set("Proxy-Authorization", "Basic "+credentials)
+	}
+	if err := req.Write(conn); err != nil {
+		return err
+	}
+
+	resp, err := http.ReadResponse(bufio.NewReader(conn), req)
+	if err != nil {
+		return fmt.Errorf("invalid proxy response: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode == http.StatusProxyAuthRequired {
+		return fmt.Errorf("proxy authentication failed: %s", resp.Status)
+	}
+	return nil
+}
+
+// checkSOCKS5 与SOCKS5代理协商认证方法，有认证信息时完成用户名密码认证
+func checkSOCKS5(conn net.Conn, auth *url.Userinfo) error {
+	greeting := []byte{5, 1, 0}
+	if auth != nil {
+		greeting = []byte{5, 2, 0, 2}
+	}
+	if _, err := conn.Write(greeting); err != nil {
+		return err
+	}
+
+	reply := make([]byte, 2)
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		return fmt.Errorf("invalid proxy response: %w", err)
+	}
+	if reply[0] != 5 {
+		return fmt.Errorf("invalid proxy response: not a SOCKS5 proxy")
+	}
+	switch {
+	case reply[1] == 0:
+		return nil
+	case reply[1] != 2:
+		return fmt.Errorf("proxy authentication failed: unsupported method %d", reply[1])
+	case auth == nil:
+		return fmt.Errorf("proxy authentication failed: credentials required")
+	}
+
+	// 用户名密码认证（RFC 1929）
+	username := auth.Username()
+	password, _ := auth.Password()
+	if len(username) > 255 || len(password) > 255 {
+		return fmt.Errorf("proxy authentication failed: credentials too long")
+	}
+	request := append([]byte{1, byte(len(username))}, username...)
+	request = append(append(request, byte(len(password))), password...)
+	if _, err := conn.Write(request); err != nil {
+		return err
+	}
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		return fmt.Errorf("invalid proxy response: %w", err)
+	}
+	if reply[1] != 0 {
+		return fmt.Errorf("proxy authentication failed: credentials rejected")
+	}
+	return nil
+}
+
+// withProxy 在上下文中保存本次请求使用的代理
+func withProxy(ctx context.Context, proxy *url.URL) context.Context {
+	return context.WithValue(ctx, proxyKey{}, proxy)
+}
+
+// contextProxy 作为Transport.Proxy，使用请求上下文中保存的代理
+func contextProxy(req *http.Request) (*url.URL, error) {
+	return proxyFromContext(req.Context()), nil
+}
+
+// proxyFromContext 获取上下文中保存的代理，没有代理时返回nil
+func proxyFromContext(ctx context.Context) *url.URL {
+	proxy, _ := ctx.Value(proxyKey{}).(*url.URL)
+	return proxy
+}
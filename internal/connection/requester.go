@@ -1,12 +1,22 @@
 package connection
 
 import (
+	"context"
 	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/url"
+	"os"
+	"sort"
 	"strings"
+	"sync/atomic"
 	"time"
+
+	"HiDir/internal/common"
 )
 
 // Response 存储HTTP响应
@@ -18,10 +28,47 @@ type Response struct {
 	Headers  map[string][]string
 	Path     string
 	FullPath string
-	Redirect string
-	History  []string
+	Redirect string        // 最后一次重定向的目标
+	History  []RedirectHop // 重定向经过的每一跳，不包括最终响应
+
+	// 停止跟随重定向的原因，正常结束时为nil
+	RedirectErr error
+
+	// 替换占位符的值，如 FUZZ=admin，不使用占位符时为空
+	Payload string
+
+	// 实际发送的请求，重定向之前的第一个请求
+	Request *SentRequest
+
+	// 根据响应内容计算的统计信息
+	Words       int    // 单词数
+	Lines       int    // 行数
+	Title       string // HTML标题
+	ContentType string // 不含参数的Content-Type
+	BodyHash    string // 响应体的SHA-256
+}
+
+// SentRequest 实际发送的请求，占位符已被替换，用于重放
+type SentRequest struct {
+	Method  string
+	URL     string
+	Headers map[string]string
+	Data    string
+}
+
+// RedirectHop 重定向中的一跳
+type RedirectHop struct {
+	URL    string
+	Status int
 }
 
+// 停止跟随重定向的原因
+var (
+	ErrRedirectLoop      = errors.New("redirect loop")
+	ErrCrossHostRedirect = errors.New("cross-host redirect")
+	ErrTooManyRedirects  = errors.New("too many redirects")
+)
+
 // Requester 处理HTTP请求
 type Requester struct {
 	client    *http.Client
@@ -32,28 +79,113 @@ type Requester struct {
 	headers   map[string]string
 	data      string
 	method    string
+
+	followRedirects bool
+	address         atomic.Value // 直接连接时使用的主机（string），为空时连接URL中的主机
+	proxies         *ProxyPool
+	keywords        []string // 请求中出现的占位符
+}
+
+// 默认超时
+const defaultTimeout = 7500 * time.Millisecond
+
+// TransportOptions HTTP传输设置
+type TransportOptions struct {
+	Timeout       time.Duration // 连接超时，包括TLS握手
+	ReadTimeout   time.Duration // 等待响应超时，为0时与连接超时相同
+	CertFile      string        // PEM格式的客户端证书
+	KeyFile       string        // PEM格式的客户端私钥，为空时从证书文件中读取
+	CAFile        string        // 验证服务器证书使用的CA证书
+	MinTLSVersion uint16        // 最低TLS版本，为0时使用默认值
+	ServerName    string        // TLS握手时发送的SNI
+	VerifyTLS     bool          // 是否验证服务器证书
 }
 
 // NewRequester 创建新的Requester实例
 func NewRequester() *Requester {
+	r := &Requester{
+		headers: make(map[string]string),
+		method:  "GET",
+	}
+	// 默认设置不涉及文件，不会失败
+	r.SetTransport(TransportOptions{})
+
+	return r
+}
+
+// SetTransport 根据设置重新创建HTTP客户端
+func (r *Requester) SetTransport(options TransportOptions) error {
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: !options.VerifyTLS, // 默认跳过证书验证
+		MinVersion:         options.MinTLSVersion,
+		ServerName:         options.ServerName,
+	}
+
+	// 客户端证书
+	if options.CertFile != "" {
+		keyFile := options.KeyFile
+		if keyFile == "" {
+			keyFile = options.CertFile
+		}
+		cert, err := tls.LoadX509KeyPair(options.CertFile, keyFile)
+		if err != nil {
+			return fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	} else if options.KeyFile != "" {
+		return fmt.Errorf("client private key given without a certificate")
+	}
+
+	// CA证书，在系统证书的基础上添加
+	if options.CAFile != "" {
+		pem, err := os.ReadFile(options.CAFile)
+		if err != nil {
+			return fmt.Errorf("failed to read CA file: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificates found in CA file %s", options.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	timeout := options.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	readTimeout := options.ReadTimeout
+	if readTimeout <= 0 {
+		readTimeout = timeout
+	}
+
 	// 创建自定义的Transport
+	dialer := &net.Dialer{Timeout: timeout}
 	transport := &http.Transport{
-		TLSClientConfig: &tls.Config{InsecureSkipVerify: true}, // 跳过证书验证
-		MaxIdleConns:    100,
-		IdleConnTimeout: 90 * time.Second,
+		Proxy: contextProxy,
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return dialer.DialContext(ctx, network, r.dialAddress(ctx, addr))
+		},
+		TLSClientConfig:       tlsConfig,
+		TLSHandshakeTimeout:   timeout,
+		ResponseHeaderTimeout: readTimeout,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
 	}
 
 	// 创建HTTP客户端
-	client := &http.Client{
+	r.client = &http.Client{
 		Transport: transport,
-		Timeout:   7500 * time.Millisecond, // 默认超时
+		Timeout:   timeout + readTimeout, // 包括读取响应内容
+		// 不自动跟随重定向，由Request处理
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
 	}
 
-	return &Requester{
-		client:  client,
-		headers: make(map[string]string),
-		method:  "GET",
-	}
+	return nil
 }
 
 // SetURL 设置目标URL
@@ -61,6 +193,31 @@ func (r *Requester) SetURL(url string) {
 	r.url = url
 }
 
+// SetAddress 设置直接连接的服务器地址，请求仍使用URL中的主机名作为Host头和SNI，
+// 通过代理的请求不受影响。修改前已发出的请求可能仍连接原来的地址
+func (r *Requester) SetAddress(address string) {
+	r.address.Store(address)
+	if transport, ok := r.client.Transport.(*http.Transport); ok {
+		transport.CloseIdleConnections()
+	}
+}
+
+// dialAddress 直接连接时将地址中的主机替换为指定的服务器地址，保留端口
+func (r *Requester) dialAddress(ctx context.Context, addr string) string {
+	if proxyFromContext(ctx) != nil {
+		return addr
+	}
+	address, _ := r.address.Load().(string)
+	if address == "" {
+		return addr
+	}
+	_, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	return net.JoinHostPort(address, port)
+}
+
 // SetAuth 设置认证信息
 func (r *Requester) SetAuth(authType, auth string) {
 	r.authType = authType
@@ -72,6 +229,16 @@ func (r *Requester) SetProxyAuth(proxyAuth string) {
 	r.proxyAuth = proxyAuth
 }
 
+// SetProxyPool 设置代理池，每个请求从代理池中选择一个代理
+func (r *Requester) SetProxyPool(pool *ProxyPool) {
+	r.proxies = pool
+}
+
+// SetKeywords 设置请求中的占位符，设置后Request不再将路径追加到URL，而是替换占位符
+func (r *Requester) SetKeywords(keywords []string) {
+	r.keywords = keywords
+}
+
 // SetHeaders 设置请求头
 func (r *Requester) SetHeaders(headers map[string]string) {
 	r.headers = headers
@@ -82,15 +249,33 @@ func (r *Requester) SetData(data string) {
 	r.data = data
 }
 
+// SetFollowRedirects 设置是否跟随重定向
+func (r *Requester) SetFollowRedirects(follow bool) {
+	r.followRedirects = follow
+}
+
 // SetMethod 设置HTTP方法
 func (r *Requester) SetMethod(method string) {
 	r.method = method
 }
 
 // Request 发送HTTP请求
+//
+// 未启用跟随重定向时直接返回重定向响应；启用时手动跟随，最多common.MAX_REDIRECTS次，
+// 并在History中记录每一跳。遇到重定向循环、跨主机重定向或超过次数限制时停止跟随，
+// 返回最后一个重定向响应并设置RedirectErr。
 func (r *Requester) Request(path string, proxy ...string) (*Response, error) {
-	// 构建完整URL
-	fullPath := r.url
+	// 请求中有占位符时用路径替换所有占位符
+	if len(r.keywords) > 0 {
+		values := make(map[string]string, len(r.keywords))
+		for _, keyword := range r.keywords {
+			values[keyword] = path
+		}
+		return r.RequestValues(values, proxy...)
+	}
+
+	// 构建完整URL，路径插入在目标的查询字符串之前
+	fullPath, query, _ := strings.Cut(r.url, "?")
 	if !strings.HasSuffix(fullPath, "/") {
 		fullPath += "/"
 	}
@@ -98,15 +283,195 @@ func (r *Requester) Request(path string, proxy ...string) (*Response, error) {
 		path = strings.TrimPrefix(path, "/")
 	}
 	fullPath += path
+	if query != "" {
+		if strings.Contains(path, "?") {
+			fullPath += "&" + query
+		} else {
+			fullPath += "?" + query
+		}
+	}
+
+	return r.do(fullPath, path, r.headers, r.data, proxy...)
+}
+
+// RequestValues 将URL、请求头和请求数据中的占位符替换为对应的值后发送请求
+func (r *Requester) RequestValues(values map[string]string, proxy ...string) (*Response, error) {
+	// 较长的占位符先替换，避免W1替换W10的一部分
+	keywords := make([]string, 0, len(values))
+	for keyword := range values {
+		keywords = append(keywords, keyword)
+	}
+	sort.Slice(keywords, func(i, j int) bool {
+		if len(keywords[i]) != len(keywords[j]) {
+			return len(keywords[i]) > len(keywords[j])
+		}
+		return keywords[i] < keywords[j]
+	})
+	var pairs, payload []string
+	for _, keyword := range keywords {
+		pairs = append(pairs, keyword, values[keyword])
+	}
+	sort.Strings(keywords)
+	for _, keyword := range keywords {
+		payload = append(payload, keyword+"="+values[keyword])
+	}
+	replacer := strings.NewReplacer(pairs...)
+
+	fullPath := replacer.Replace(r.url)
+	headers := make(map[string]string, len(r.headers))
+	for key, value := range r.headers {
+		headers[replacer.Replace(key)] = replacer.Replace(value)
+	}
+
+	// 路径为替换后URL中主机之后的部分
+	path := fullPath
+	if parsed, err := url.Parse(fullPath); err == nil {
+		path = strings.TrimPrefix(parsed.RequestURI(), "/")
+	}
+
+	response, err := r.do(fullPath, path, headers, replacer.Replace(r.data), proxy...)
+	if err != nil {
+		return nil, err
+	}
+	response.Payload = strings.Join(payload, ", ")
+	return response, nil
+}
 
-	// 创建请求
-	req, err := http.NewRequest(r.method, fullPath, strings.NewReader(r.data))
+// do 发送请求并按设置处理重定向
+func (r *Requester) do(fullPath, path string, headers map[string]string, data string, proxy ...string) (*Response, error) {
+	// 指定的代理优先于代理池，重定向的每一跳使用同一个代理
+	proxyURL, pooled, err := r.selectProxy(proxy...)
 	if err != nil {
 		return nil, err
 	}
 
-	// 设置请求头
-	for key, value := range r.headers {
+	request := &SentRequest{Method: r.method, URL: fullPath, Headers: headers, Data: data}
+	var (
+		method   = r.method
+		current  = fullPath
+		history  []RedirectHop
+		visited  = map[string]bool{fullPath: true}
+		redirect string
+	)
+	for {
+		withAuth := r.withProxyAuth(proxyURL)
+		resp, content, err := r.send(method, current, data, headers, withAuth)
+		if err != nil {
+			if pooled {
+				r.proxies.Fail(proxyURL, withAuth.User, current)
+			}
+			return nil, err
+		}
+
+		response := &Response{
+			Status:   resp.StatusCode,
+			Content:  string(content),
+			Length:   int64(len(content)),
+			Headers:  resp.Header,
+			Path:     path,
+			FullPath: fullPath,
+			Redirect: redirect,
+			History:  history,
+			Request:  request,
+		}
+
+		location := resp.Header.Get("Location")
+		if !isRedirect(resp.StatusCode) || location == "" {
+			response.computeMetrics()
+			return response, nil
+		}
+
+		// 重定向响应
+		response.Redirect = location
+		response.History = append(history, RedirectHop{URL: current, Status: resp.StatusCode})
+		if !r.followRedirects {
+			response.computeMetrics()
+			return response, nil
+		}
+
+		next, err := resolveLocation(current, location)
+		switch {
+		case err != nil:
+			return nil, fmt.Errorf("invalid redirect location %q: %w", location, err)
+		case visited[next.String()]:
+			response.RedirectErr = ErrRedirectLoop
+		case !strings.EqualFold(next.Hostname(), hostname(current)):
+			response.RedirectErr = ErrCrossHostRedirect
+		case len(response.History) >= common.MAX_REDIRECTS:
+			response.RedirectErr = ErrTooManyRedirects
+		}
+		if response.RedirectErr != nil {
+			response.computeMetrics()
+			return response, nil
+		}
+
+		// 与浏览器一致，307和308以外的重定向改为不带请求体的GET请求
+		if resp.StatusCode != http.StatusTemporaryRedirect && resp.StatusCode != http.StatusPermanentRedirect && method != "HEAD" {
+			method = "GET"
+			data = ""
+		}
+		current = next.String()
+		visited[current] = true
+		history = response.History
+		redirect = current
+	}
+}
+
+// Replay 通过指定的代理重新发送请求，使用原请求的方法、请求头和请求数据，不跟随重定向
+//
+// 重放代理通常是本地的Burp或ZAP，不使用代理认证设置。
+func (r *Requester) Replay(request *SentRequest, proxy string) error {
+	proxyURL, err := ParseProxy(proxy)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = r.send(request.Method, request.URL, request.Data, request.Headers, proxyURL)
+	return err
+}
+
+// selectProxy 选择本次请求使用的代理，返回代理是否来自代理池
+func (r *Requester) selectProxy(proxy ...string) (*url.URL, bool, error) {
+	switch {
+	case len(proxy) > 0 && proxy[0] != "":
+		proxyURL, err := ParseProxy(proxy[0])
+		return proxyURL, false, err
+	case r.proxies != nil:
+		proxyURL, err := r.proxies.Next()
+		return proxyURL, true, err
+	}
+	return nil, false, nil
+}
+
+// withProxyAuth 代理URL中没有认证信息时添加代理认证设置
+func (r *Requester) withProxyAuth(proxy *url.URL) *url.URL {
+	if proxy == nil || proxy.User != nil || r.proxyAuth == "" {
+		return proxy
+	}
+
+	withAuth := *proxy
+	if parts := strings.SplitN(r.proxyAuth, ":", 2); len(parts) == 2 {
+		withAuth.User = url.UserPassword(parts[0], parts[1])
+	} else {
+		withAuth.User = url.User(r.proxyAuth)
+	}
+	return &withAuth
+}
+
+// send 发送单个请求并读取响应内容
+func (r *Requester) send(method, target, data string, headers map[string]string, proxy *url.URL) (*http.Response, []byte, error) {
+	// 创建请求，使用的代理保存在上下文中
+	req, err := http.NewRequestWithContext(withProxy(context.Background(), proxy), method, target, strings.NewReader(data))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// 设置请求头，Host头需要单独设置
+	for key, value := range headers {
+		if strings.EqualFold(key, "Host") {
+			req.Host = value
+			continue
+		}
 		req.Header.Set(key, value)
 	}
 
@@ -123,49 +488,51 @@ func (r *Requester) Request(path string, proxy ...string) (*Response, error) {
 		}
 	}
 
-	// 设置代理
-	if len(proxy) > 0 && proxy[0] != "" {
-		proxyURL, err := url.Parse(proxy[0])
-		if err == nil {
-			req.URL.Host = proxyURL.Host
-			req.URL.Scheme = proxyURL.Scheme
-		}
-	}
-
 	// 发送请求
 	resp, err := r.client.Do(req)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	defer resp.Body.Close()
 
 	// 读取响应内容
 	content, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	// 处理重定向
-	redirect := ""
-	history := []string{}
-	if resp.StatusCode >= 300 && resp.StatusCode < 400 {
-		redirect = resp.Header.Get("Location")
-		if redirect != "" {
-			history = append(history, fullPath)
-		}
+	return resp, content, nil
+}
+
+// isRedirect 检查状态码是否为需要跟随的重定向
+func isRedirect(status int) bool {
+	switch status {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther,
+		http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return true
 	}
+	return false
+}
 
-	// 构建响应对象
-	response := &Response{
-		Status:   resp.StatusCode,
-		Content:  string(content),
-		Length:   int64(len(content)),
-		Headers:  resp.Header,
-		Path:     path,
-		FullPath: fullPath,
-		Redirect: redirect,
-		History:  history,
+// resolveLocation 将Location解析为绝对URL
+func resolveLocation(base, location string) (*url.URL, error) {
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return nil, err
+	}
+	next, err := baseURL.Parse(location)
+	if err != nil {
+		return nil, err
 	}
+	next.Fragment = ""
+	return next, nil
+}
 
-	return response, nil
+// hostname 获取URL的主机名
+func hostname(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return parsed.Hostname()
 }
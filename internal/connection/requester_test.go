@@ -0,0 +1,292 @@
+package connection
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRequesterRedirects(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/admin":
+			http.Redirect(w, r, "/admin/", http.StatusMovedPermanently)
+		case "/admin/":
+			http.Redirect(w, r, "/admin/login", http.StatusFound)
+		case "/admin/login":
+			w.Write([]byte("login"))
+		case "/loop":
+			http.Redirect(w, r, "/loop2", http.StatusFound)
+		case "/loop2":
+			http.Redirect(w, r, "/loop", http.StatusFound)
+		case "/external":
+			http.Redirect(w, r, "http://login.example.org/", http.StatusFound)
+		case "/method":
+			w.Write([]byte(r.Method))
+		case "/temporary":
+			http.Redirect(w, r, "/method", http.StatusTemporaryRedirect)
+		case "/see-other":
+			http.Redirect(w, r, "/method", http.StatusSeeOther)
+		default:
+			http.Redirect(w, r, r.URL.Path+"x", http.StatusFound)
+		}
+	}))
+	defer server.Close()
+
+	newRequester := func(follow bool) *Requester {
+		requester := NewRequester()
+		requester.SetURL(server.URL)
+		requester.SetFollowRedirects(follow)
+		return requester
+	}
+
+	// 测试用例1：默认不跟随重定向
+	t.Run("NotFollowed", func(t *testing.T) {
+		response, err := newRequester(false).Request("admin")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if response.Status != 301 || response.Redirect != "/admin/" {
+			t.Errorf("Expected 301 to /admin/, got %d to %q", response.Status, response.Redirect)
+		}
+		expected := []RedirectHop{{URL: server.URL + "/admin", Status: 301}}
+		if !reflect.DeepEqual(response.History, expected) {
+			t.Errorf("Expected history %v, got %v", expected, response.History)
+		}
+	})
+
+	// 测试用例2：跟随重定向并记录每一跳
+	t.Run("Followed", func(t *testing.T) {
+		response, err := newRequester(true).Request("admin")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if response.Status != 200 || response.Content != "login" || response.RedirectErr != nil {
+			t.Errorf("Expected final 200 response, got %d %q %v", response.Status, response.Content, response.RedirectErr)
+		}
+		if response.FullPath != server.URL+"/admin" || response.Redirect != server.URL+"/admin/login" {
+			t.Errorf("Unexpected URLs %q -> %q", response.FullPath, response.Redirect)
+		}
+		expected := []RedirectHop{{URL: server.URL + "/admin", Status: 301}, {URL: server.URL + "/admin/", Status: 302}}
+		if !reflect.DeepEqual(response.History, expected) {
+			t.Errorf("Expected history %v, got %v", expected, response.History)
+		}
+	})
+
+	// 测试用例3：停止跟随的原因
+	t.Run("Stopped", func(t *testing.T) {
+		for path, expected := range map[string]error{
+			"loop":     ErrRedirectLoop,
+			"external": ErrCrossHostRedirect,
+			"x":        ErrTooManyRedirects,
+		} {
+			response, err := newRequester(true).Request(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if response.RedirectErr != expected || response.Status != 302 {
+				t.Errorf("Expected %v for %s, got %d %v", expected, path, response.Status, response.RedirectErr)
+			}
+		}
+	})
+
+	// 测试用例4：307保留请求方法，303改为GET
+	t.Run("Method", func(t *testing.T) {
+		requester := newRequester(true)
+		requester.SetMethod("POST")
+		for path, expected := range map[string]string{"temporary": "POST", "see-other": "GET"} {
+			response, err := requester.Request(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if response.Content != expected {
+				t.Errorf("Expected %s after redirect from %s, got %s", expected, path, response.Content)
+			}
+		}
+	})
+}
+
+// writeClientCertificate 生成自签名的客户端证书，返回证书和私钥文件路径
+func writeClientCertificate(t *testing.T) (*x509.Certificate, string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "hidir"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "client.crt"), filepath.Join(dir, "client.key")
+	writePEM(t, certFile, "CERTIFICATE", der)
+	writePEM(t, keyFile, "EC PRIVATE KEY", keyDER)
+	return cert, certFile, keyFile
+}
+
+// writePEM 将数据以PEM格式写入文件
+func writePEM(t *testing.T, path, blockType string, data []byte) {
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: data}), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRequesterTransport(t *testing.T) {
+	clientCert, certFile, keyFile := writeClientCertificate(t)
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCert)
+
+	var serverName string
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		serverName = r.TLS.ServerName
+		w.Write([]byte("ok"))
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs, MaxVersion: tls.VersionTLS12}
+	server.StartTLS()
+	defer server.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.crt")
+	writePEM(t, caFile, "CERTIFICATE", server.Certificate().Raw)
+
+	request := func(options TransportOptions) (*Response, error) {
+		requester := NewRequester()
+		if err := requester.SetTransport(options); err != nil {
+			return nil, err
+		}
+		requester.SetURL(server.URL)
+		return requester.Request("")
+	}
+
+	// 测试用例1：使用客户端证书
+	t.Run("ClientCertificate", func(t *testing.T) {
+		if _, err := request(TransportOptions{}); err == nil {
+			t.Error("Expected error without client certificate")
+		}
+		response, err := request(TransportOptions{CertFile: certFile, KeyFile: keyFile})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if response.Status != 200 {
+			t.Errorf("Expected status 200, got %d", response.Status)
+		}
+	})
+
+	// 测试用例2：严格验证服务器证书
+	t.Run("VerifyTLS", func(t *testing.T) {
+		if _, err := request(TransportOptions{CertFile: certFile, KeyFile: keyFile, VerifyTLS: true}); err == nil {
+			t.Error("Expected error for untrusted server certificate")
+		}
+		if _, err := request(TransportOptions{CertFile: certFile, KeyFile: keyFile, CAFile: caFile, VerifyTLS: true}); err != nil {
+			t.Errorf("Expected server trusted with CA file, got %v", err)
+		}
+	})
+
+	// 测试用例3：最低TLS版本和SNI
+	t.Run("TLSOptions", func(t *testing.T) {
+		if _, err := request(TransportOptions{CertFile: certFile, KeyFile: keyFile, MinTLSVersion: tls.VersionTLS13}); err == nil {
+			t.Error("Expected error when the server does not support the minimum TLS version")
+		}
+		if _, err := request(TransportOptions{CertFile: certFile, KeyFile: keyFile, ServerName: "internal.example.com"}); err != nil {
+			t.Fatal(err)
+		}
+		if serverName != "internal.example.com" {
+			t.Errorf("Expected SNI internal.example.com, got %q", serverName)
+		}
+	})
+
+	// 测试用例4：无效的证书文件
+	t.Run("InvalidFiles", func(t *testing.T) {
+		for _, options := range []TransportOptions{
+			{CertFile: filepath.Join(t.TempDir(), "missing.crt")},
+			{KeyFile: keyFile},
+			{CAFile: keyFile},
+		} {
+			if err := NewRequester().SetTransport(options); err == nil {
+				t.Errorf("Expected error for %+v", options)
+			}
+		}
+	})
+}
+
+func TestRequesterAddress(t *testing.T) {
+	var (
+		mutex sync.Mutex
+		hosts []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		hosts = append(hosts, r.Host)
+		mutex.Unlock()
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	_, port, _ := net.SplitHostPort(strings.TrimPrefix(server.URL, "http://"))
+	requester := NewRequester()
+	requester.SetURL("http://example.test:" + port + "/")
+	requester.SetAddress("127.0.0.1")
+
+	// 请求进行中修改地址，需配合 -race 运行
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				requester.SetAddress("127.0.0.1")
+			}
+		}
+	}()
+	for i := 0; i < 5; i++ {
+		if _, err := requester.Request("admin"); err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+	}
+	close(done)
+	wg.Wait()
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	for _, host := range hosts {
+		if host != "example.test:"+port {
+			t.Errorf("Expected Host header example.test:%s, got %s", port, host)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+// 多个字典的组合方式
+const (
+	FuzzModeClusterbomb = "clusterbomb" // 所有字典单词的全部组合
+	FuzzModePitchfork   = "pitchfork"   // 各字典按位置一一对应，以最短的字典为准
+)
+
+// WordSource 单词来源，Fuzzer按顺序请求其中的单词
+type WordSource interface {
+	Next() (string, bool)
+	Index() int
+	Len() int
+	Reset()
+}
+
+// Combinator 将绑定到不同占位符的多个字典组合为每个请求中各占位符的值
+type Combinator struct {
+	keywords     []string
+	dictionaries []*Dictionary
+	pitchfork    bool
+	current      []string // 当前组合中每个字典的单词，尚未开始时为nil
+	index        int
+}
+
+// NewCombinator 创建新的Combinator实例，keywords与dictionaries一一对应
+func NewCombinator(keywords []string, dictionaries []*Dictionary, mode string) (*Combinator, error) {
+	combinator := &Combinator{keywords: keywords, dictionaries: dictionaries}
+	switch mode {
+	case "", FuzzModeClusterbomb:
+	case FuzzModePitchfork:
+		combinator.pitchfork = true
+	default:
+		return nil, fmt.Errorf("invalid fuzz mode %q, expected %s or %s", mode, FuzzModeClusterbomb, FuzzModePitchfork)
+	}
+
+	return combinator, nil
+}
+
+// Next 生成下一个组合，返回用于显示的各占位符的值
+func (c *Combinator) Next() (string, bool) {
+	if !c.advance() {
+		return "", false
+	}
+	c.index++
+
+	parts := make([]string, len(c.keywords))
+	for i, keyword := range c.keywords {
+		parts[i] = keyword + "=" + c.current[i]
+	}
+	return strings.Join(parts, ", "), true
+}
+
+// advance 推进到下一个组合
+func (c *Combinator) advance() bool {
+	// 第一个组合以及pitchfork模式下，每个字典各取下一个单词
+	if c.current == nil || c.pitchfork {
+		current := make([]string, len(c.dictionaries))
+		for i, dictionary := range c.dictionaries {
+			word, ok := dictionary.Next()
+			if !ok {
+				return false
+			}
+			current[i] = word
+		}
+		c.current = current
+		return true
+	}
+
+	// clusterbomb模式下像里程表一样从最后一个字典开始推进，字典用完后从头开始并进位
+	for i := len(c.dictionaries) - 1; i >= 0; i-- {
+		if word, ok := c.dictionaries[i].Next(); ok {
+			c.current[i] = word
+			return true
+		}
+		if i == 0 {
+			return false
+		}
+		c.dictionaries[i].Reset()
+		c.current[i], _ = c.dictionaries[i].Next()
+	}
+	return false
+}
+
+// Values 获取最近一次Next生成的组合中各占位符的值
+func (c *Combinator) Values() map[string]string {
+	values := make(map[string]string, len(c.keywords))
+	for i, keyword := range c.keywords {
+		values[keyword] = c.current[i]
+	}
+	return values
+}
+
+// Index 获取已生成的组合数
+func (c *Combinator) Index() int {
+	return c.index
+}
+
+// Len 获取组合总数
+func (c *Combinator) Len() int {
+	total := 0
+	for i, dictionary := range c.dictionaries {
+		switch {
+		case i == 0:
+			total = dictionary.Len()
+		case c.pitchfork:
+			total = min(total, dictionary.Len())
+		default:
+			total *= dictionary.Len()
+		}
+	}
+	return total
+}
+
+// Reset 从第一个组合重新开始
+func (c *Combinator) Reset() {
+	for _, dictionary := range c.dictionaries {
+		dictionary.Reset()
+	}
+	c.current = nil
+	c.index = 0
+}
@@ -0,0 +1,80 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"HiDir/internal/parse"
+)
+
+func TestCombinator(t *testing.T) {
+	dir := t.TempDir()
+	newDictionary := func(name string, words ...string) *Dictionary {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(strings.Join(words, "\n")), 0644); err != nil {
+			t.Fatal(err)
+		}
+		dictionary := NewDictionary(path)
+		dictionary.SetOptions(&parse.Options{})
+		if err := dictionary.Load(); err != nil {
+			t.Fatal(err)
+		}
+		return dictionary
+	}
+	collect := func(combinator *Combinator) []string {
+		var result []string
+		for {
+			if _, ok := combinator.Next(); !ok {
+				return result
+			}
+			values := combinator.Values()
+			result = append(result, values["W1"]+":"+values["W2"])
+		}
+	}
+
+	tests := []struct {
+		name     string
+		mode     string
+		expected []string
+	}{
+		{name: "Clusterbomb", mode: FuzzModeClusterbomb, expected: []string{"admin:123", "admin:pass", "root:123", "root:pass", "guest:123", "guest:pass"}},
+		{name: "Pitchfork", mode: FuzzModePitchfork, expected: []string{"admin:123", "root:pass"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := newDictionary("users.txt", "admin", "root", "guest")
+			passwords := newDictionary("passwords.txt", "123", "pass")
+			combinator, err := NewCombinator([]string{"W1", "W2"}, []*Dictionary{users, passwords}, tt.mode)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if combinator.Len() != len(tt.expected) {
+				t.Errorf("Expected length %d, got %d", len(tt.expected), combinator.Len())
+			}
+			if got := collect(combinator); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+			if combinator.Index() != len(tt.expected) {
+				t.Errorf("Expected index %d, got %d", len(tt.expected), combinator.Index())
+			}
+
+			// 重新开始后生成相同的组合
+			combinator.Reset()
+			if got := collect(combinator); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Expected %v after reset, got %v", tt.expected, got)
+			}
+		})
+	}
+
+	// 无效的组合方式
+	t.Run("InvalidMode", func(t *testing.T) {
+		if _, err := NewCombinator(nil, nil, "sniper"); err == nil {
+			t.Error("Expected error for invalid mode")
+		}
+	})
+}
@@ -0,0 +1,146 @@
+package core
+
+import (
+	"net/url"
+	"path"
+	"strings"
+
+	"HiDir/internal/common"
+	"HiDir/internal/connection"
+	"HiDir/internal/utils"
+)
+
+// Comparer 计算响应与基准响应的相似度，通配符测试与 --exclude-response 共用
+type Comparer struct {
+	threshold float64
+	status    int
+	content   string   // 归一化后的基准内容
+	static    []string // 基准内容中的静态部分
+	dynamic   int      // 基准内容中动态部分的数量
+}
+
+// ComparerSample 基准响应及其请求路径
+type ComparerSample struct {
+	Response *connection.Response
+	Path     string
+	Values   []string // 替换占位符的值，比较前从内容中去除
+}
+
+// NewComparer 根据基准响应创建Comparer，提供两个基准响应时会排除二者之间不同的部分
+func NewComparer(threshold float64, first ComparerSample, others ...ComparerSample) *Comparer {
+	if threshold <= 0 || threshold > 1 {
+		threshold = common.DEFAULT_SIMILARITY_THRESHOLD
+	}
+
+	content := normalize(first.Response.Content, first.Path, first.Values...)
+	static := strings.Fields(content)
+	total := len(static)
+
+	for _, other := range others {
+		otherContent := normalize(other.Response.Content, other.Path, other.Values...)
+		if otherContent == content {
+			continue
+		}
+		static = utils.CommonTokens(static, strings.Fields(otherContent))
+	}
+
+	return &Comparer{
+		threshold: threshold,
+		status:    first.Response.Status,
+		content:   content,
+		static:    static,
+		dynamic:   total - len(static),
+	}
+}
+
+// Score 计算响应与基准的相似度，范围为0到1，values为请求中替换占位符的值
+func (c *Comparer) Score(response *connection.Response, path string, values ...string) float64 {
+	content := normalize(response.Content, path, values...)
+	if content == c.content {
+		return 1
+	}
+
+	tokens := strings.Fields(content)
+	if len(c.static) == 0 && len(tokens) == 0 {
+		return 1
+	}
+
+	// 响应中与基准动态部分对应的内容不计入比较
+	length := len(tokens) - c.dynamic
+	if length < len(c.static) {
+		length = len(c.static)
+	}
+
+	matched := len(utils.CommonTokens(c.static, tokens))
+	return 2 * float64(matched) / float64(len(c.static)+length)
+}
+
+// Similar 检查响应是否与基准相似
+func (c *Comparer) Similar(response *connection.Response, path string, values ...string) bool {
+	_, similar := c.Compare(response, path, values...)
+	return similar
+}
+
+// Compare 计算相似度并检查响应是否与基准相似，状态码不同时不计算相似度，返回0
+func (c *Comparer) Compare(response *connection.Response, path string, values ...string) (float64, bool) {
+	if response.Status != c.status {
+		return 0, false
+	}
+	score := c.Score(response, path, values...)
+	return score, score >= c.threshold
+}
+
+// normalize 归一化响应内容，去除请求路径及其最后一段的反射，以及替换占位符的值的反射
+//
+// 反射的路径与值只按完整单词去除，即使很短（如 /js）也不会误伤其他单词。
+func normalize(content, requestPath string, values ...string) string {
+	requestPath = strings.Trim(requestPath, "/")
+	reflected := append([]string{requestPath, path.Base(requestPath)}, values...)
+
+	for _, value := range reflected {
+		if value == "." {
+			continue
+		}
+		content = removeValue(content, value)
+		for _, escaped := range []string{url.QueryEscape(value), url.PathEscape(value)} {
+			if escaped != value {
+				content = removeValue(content, escaped)
+			}
+		}
+	}
+
+	return utils.NormalizeContent(content)
+}
+
+// removeValue 去除内容中作为完整单词出现的值
+//
+// 占位符的值可能很短，如 www，只去除前后不是字母、数字或下划线的出现，避免误伤其他单词。
+func removeValue(content, value string) string {
+	if value == "" {
+		return content
+	}
+
+	var builder strings.Builder
+	last := 0 // 尚未写入的内容的起始位置
+	for offset := 0; ; {
+		i := strings.Index(content[offset:], value)
+		if i < 0 {
+			break
+		}
+		start, end := offset+i, offset+i+len(value)
+		if (start > 0 && isWordByte(content[start-1])) || (end < len(content) && isWordByte(content[end])) {
+			offset = start + 1
+			continue
+		}
+		builder.WriteString(content[last:start])
+		last, offset = end, end
+	}
+	builder.WriteString(content[last:])
+
+	return builder.String()
+}
+
+// isWordByte 检查字符是否为字母、数字或下划线
+func isWordByte(b byte) bool {
+	return b == '_' || (b >= '0' && b <= '9') || (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z')
+}
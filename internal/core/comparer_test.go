@@ -0,0 +1,146 @@
+package core
+
+import (
+	"strings"
+	"testing"
+
+	"HiDir/internal/connection"
+)
+
+func TestComparerSimilar(t *testing.T) {
+	page := func(path, token, time string) *connection.Response {
+		return &connection.Response{
+			Status: 200,
+			Content: `<html><head><title>Not Found</title></head><body>
+<form><input type="hidden" name="csrf_token" value="` + token + `"></form>
+<p>The page /` + path + ` could not be found on this server.</p>
+<p>Generated at ` + time + `</p></body></html>`,
+		}
+	}
+
+	comparer := NewComparer(0, ComparerSample{
+		Response: page("errorpage", "a8f5f167f44f4964e6c998dee827110c", "2024-01-01 10:00:00"),
+		Path:     "errorpage",
+	})
+
+	// 测试用例1：只有反射路径、令牌和时间不同
+	t.Run("DynamicContent", func(t *testing.T) {
+		response := page("backup.zip", "0cc175b9c0f1b6a831c399e269772661", "2024-03-08 23:59:12")
+		if !comparer.Similar(response, "backup.zip") {
+			t.Errorf("Expected response to be similar, score %f", comparer.Score(response, "backup.zip"))
+		}
+	})
+
+	// 测试用例2：内容完全不同
+	t.Run("DifferentContent", func(t *testing.T) {
+		response := &connection.Response{Status: 200, Content: "<html><body><h1>Admin dashboard</h1></body></html>"}
+		if comparer.Similar(response, "admin") {
+			t.Errorf("Expected response not to be similar, score %f", comparer.Score(response, "admin"))
+		}
+	})
+
+	// 测试用例3：状态码不同
+	t.Run("DifferentStatus", func(t *testing.T) {
+		response := page("secret", "0cc175b9c0f1b6a831c399e269772661", "2024-03-08 23:59:12")
+		response.Status = 403
+		if comparer.Similar(response, "secret") {
+			t.Error("Expected response with different status not to be similar")
+		}
+	})
+}
+
+func TestComparerShortPath(t *testing.T) {
+	page := func(path string) *connection.Response {
+		return &connection.Response{Status: 404, Content: "<!DOCTYPE html><html><body><pre>Cannot GET /" + path + "</pre></body></html>"}
+	}
+	comparer := NewComparer(0, ComparerSample{Response: page("xkqjwvzpmnbt"), Path: "xkqjwvzpmnbt"})
+
+	// 反射的短路径也应去除
+	for _, path := range []string{"js", "css", "api", "img/js"} {
+		if !comparer.Similar(page(path), path) {
+			t.Errorf("Expected %s to be similar, score %f", path, comparer.Score(page(path), path))
+		}
+	}
+}
+
+func TestComparerValues(t *testing.T) {
+	page := func(value string) *connection.Response {
+		return &connection.Response{Status: 200, Content: "<p>Search results for q=" + value + ": nothing matched " + value + ".example.com</p>"}
+	}
+	comparer := NewComparer(0, ComparerSample{Response: page("xkqjwvzpmnbt"), Path: "search?q=xkqjwvzpmnbt", Values: []string{"xkqjwvzpmnbt"}})
+
+	// 测试用例1：去除反射的短值后与基准相同
+	t.Run("ShortValue", func(t *testing.T) {
+		if !comparer.Similar(page("www"), "search?q=www", "www") {
+			t.Errorf("Expected response to be similar, score %f", comparer.Score(page("www"), "search?q=www", "www"))
+		}
+	})
+
+	// 测试用例2：不提供值时反射的内容参与比较
+	t.Run("WithoutValues", func(t *testing.T) {
+		if score := comparer.Score(page("www"), "search?q=www"); score == 1 {
+			t.Errorf("Expected reflected value to lower the score, got %f", score)
+		}
+	})
+
+	// 测试用例3：只去除作为完整单词出现的值，包括URL编码后的值
+	t.Run("WordBoundary", func(t *testing.T) {
+		tests := []struct {
+			content, value, expected string
+		}{
+			{"www.example.com wwwroot", "www", ".example.com wwwroot"},
+			{"q=ab, about, ab_c, (ab)", "ab", "q=, about, ab_c, ()"},
+			{"ab ab", "ab", " "},
+			{"a%20b, a+b and a b", "a b", ",  and "},
+		}
+		for _, tt := range tests {
+			if got := normalize(tt.content, "", tt.value); got != tt.expected {
+				t.Errorf("normalize(%q, %q): expected %q, got %q", tt.content, tt.value, tt.expected, got)
+			}
+		}
+	})
+}
+
+func TestComparerLargePage(t *testing.T) {
+	// 不含数字的不重复单词，避免被归一化去除
+	word := func(i int) string {
+		letters := []byte{'w'}
+		for ; i > 0; i /= 26 {
+			letters = append(letters, byte('a'+i%26))
+		}
+		return string(letters)
+	}
+	var static []string
+	for i := 0; i < 6000; i++ {
+		static = append(static, word(i))
+	}
+
+	// 页首、中间和页尾都有随每次请求变化的内容
+	page := func(dynamic string) *connection.Response {
+		content := "<html><body><p>ad " + dynamic + " banner</p>\n" +
+			strings.Join(static[:3000], " ") + "\n<p>featured " + dynamic + " story</p>\n" +
+			strings.Join(static[3000:], " ") + "\n<footer>session " + dynamic + "</footer></body></html>"
+		return &connection.Response{Status: 404, Content: content}
+	}
+	comparer := NewComparer(0, ComparerSample{Response: page("alpha"), Path: "xkqjwvzpmnbt"})
+
+	// 测试用例1：只有动态内容不同
+	t.Run("DynamicContent", func(t *testing.T) {
+		response := page("charlie")
+		if !comparer.Similar(response, "js") {
+			t.Errorf("Expected response to be similar, score %f", comparer.Score(response, "js"))
+		}
+	})
+
+	// 测试用例2：同样大小但内容不同的页面
+	t.Run("DifferentContent", func(t *testing.T) {
+		var other []string
+		for i := 0; i < 6000; i++ {
+			other = append(other, "x"+word(i))
+		}
+		response := &connection.Response{Status: 404, Content: strings.Join(other, " ")}
+		if comparer.Similar(response, "admin") {
+			t.Errorf("Expected response not to be similar, score %f", comparer.Score(response, "admin"))
+		}
+	})
+}
@@ -1,41 +1,83 @@
 package core
 
 import (
+	"bufio"
+	"errors"
 	"fmt"
+	"io/fs"
+	"net/url"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
 	"strings"
+	"sync/atomic"
+	"syscall"
 	"time"
 
 	"HiDir/internal/common"
 	"HiDir/internal/connection"
 	"HiDir/internal/parse"
+	"HiDir/internal/report"
 	"HiDir/internal/utils"
+	"HiDir/internal/view"
+	"HiDir/pkg/filter"
 )
 
 // Controller 控制器
 type Controller struct {
 	requester         *connection.Requester
-	dictionary        *Dictionary
+	dictionary        WordSource
+	keywords          []string // 字典绑定的占位符
+	template          bool     // 请求中有占位符，替换占位符而不是追加路径
 	fuzzer            *Fuzzer
 	opts              *parse.Options
-	results           []*connection.Response
+	results           []*report.Result
+	reportFormat      string
+	reportOutput      report.Output // 正在写入的报告
 	targets           []string
 	startTime         time.Time
-	directories       []string
+	directories       *directoryQueue
+	currentDirectory  *directory
+	currentTarget     string
+	filters           []filter.Filter // 根据选项生成的过滤器
+	customFilters     []filter.Filter // 通过AddFilter添加的过滤器
+	recursionStatus   *parse.StatusMatcher
+	skipStatus        *parse.StatusMatcher
+	skipTarget        atomic.Bool
 	passedURLs        map[string]bool
 	errors            int
 	consecutiveErrors int
 	dictFiles         []string // 保存使用的字典文件
+	targetIndex       int      // 正在扫描的目标
+	session           *Session // 正在恢复的会话，恢复完成后为nil
+	lastSave          time.Time
+	interrupted       atomic.Bool   // 扫描被中断，Run在当前请求完成后返回
+	discardSession    atomic.Bool   // 中断时不保存会话
+	menuOpen          atomic.Bool   // 暂停菜单正在显示
+	input             *bufio.Reader // 暂停菜单读取选择的输入
+	console           *view.Console
+	printer           *view.Printer
+	replay            *replayer
+	rawRequests       map[string]*parse.RawRequest // 原始请求文件中每个目标对应的请求
+	progress          scanProgress
 }
 
 // NewController 创建新的Controller实例
 func NewController(opts *parse.Options) *Controller {
+	console := view.NewConsole(os.Stdout, false)
 	return &Controller{
 		opts:              opts,
-		results:           make([]*connection.Response, 0),
+		results:           make([]*report.Result, 0),
 		targets:           make([]string, 0),
+		directories:       &directoryQueue{},
 		dictFiles:         make([]string, 0),
 		passedURLs:        make(map[string]bool),
+		input:             bufio.NewReader(os.Stdin),
+		console:           console,
+		printer:           view.NewPrinter(console, opts),
 		errors:            0,
 		consecutiveErrors: 0,
 	}
@@ -43,32 +85,61 @@ func NewController(opts *parse.Options) *Controller {
 
 // Setup 初始化控制器
 func (c *Controller) Setup() error {
+	// 会话文件存在时恢复保存的选项和状态
+	if c.opts.SessionFile != "" {
+		session, err := LoadSession(c.opts.SessionFile)
+		if err == nil {
+			session.Options.SessionFile = c.opts.SessionFile
+			c.opts = session.Options
+			c.session = session
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+
+	// 非安静模式且输出为终端时显示进度
+	c.console = view.NewConsole(os.Stdout, !c.opts.Quiet)
+	c.printer = view.NewPrinter(c.console, c.opts)
+
 	// 初始化黑名单
 	Blacklists = GetBlacklists()
 
 	// 初始化请求器
 	c.requester = connection.NewRequester()
+	if err := c.setupTransport(); err != nil {
+		return err
+	}
 
 	// 初始化字典
-	var dictFiles []string
-	if c.opts.Wordlists != "" {
-		dictFiles = strings.Split(c.opts.Wordlists, ",")
-	} else {
-		// 默认使用dict目录下的所有txt文件
-		dictFiles = utils.GetFilesByExtension("./dict", "txt")
-		if len(dictFiles) == 0 {
-			return fmt.Errorf("no dictionary files found in dict directory")
-		}
+	if err := c.setupDictionary(); err != nil {
+		return err
 	}
 
-	// 保存字典文件路径
-	c.dictFiles = dictFiles
+	// 解析递归和跳过目标的状态码规则
+	var err error
+	if c.recursionStatus, err = parse.ParseStatusCodes(c.opts.RecursionStatusCodes); err != nil {
+		return fmt.Errorf("--recursion-status: %w", err)
+	}
+	if c.skipStatus, err = parse.ParseStatusCodes(c.opts.SkipOnStatus); err != nil {
+		return fmt.Errorf("--skip-on-status: %w", err)
+	}
 
-	c.dictionary = NewDictionary(dictFiles...)
-	if err := c.dictionary.Load(); err != nil {
+	// 根据选项建立过滤器
+	if err := c.setupFilters(); err != nil {
 		return err
 	}
 
+	// 确定报告格式，未指定时根据输出文件扩展名推断
+	if c.opts.OutputFile != "" || c.opts.OutputFormat != "" {
+		c.reportFormat = strings.ToLower(c.opts.OutputFormat)
+		if c.reportFormat == "" {
+			c.reportFormat = report.DetectFormat(c.opts.OutputFile)
+		}
+		if !report.IsSupported(c.reportFormat) {
+			return fmt.Errorf("--format: unsupported report format %q (supported: %s)", c.reportFormat, strings.Join(common.OUTPUT_FORMATS, ", "))
+		}
+	}
+
 	// 初始化fuzzer
 	c.fuzzer = NewFuzzer(c.requester, c.dictionary)
 	c.fuzzer.SetOptions(c.opts) // 设置选项
@@ -76,12 +147,40 @@ func (c *Controller) Setup() error {
 	// 设置回调
 	c.setupCallbacks()
 
-	// 处理URLs
-	c.processURLs()
+	// 处理URLs，恢复会话时使用保存的目标
+	if c.session != nil {
+		c.restoreSession()
+		if c.opts.RawFile != "" {
+			if _, err := c.loadRawRequests(); err != nil {
+				return err
+			}
+		}
+	} else if err := c.processURLs(); err != nil {
+		return err
+	}
 
 	// 设置请求头
 	c.setupHeaders()
 
+	// 设置请求方法和数据
+	if c.opts.HTTPMethod != "" {
+		c.requester.SetMethod(strings.ToUpper(c.opts.HTTPMethod))
+	}
+	data := c.opts.Data
+	if c.opts.DataFile != "" {
+		bytes, err := os.ReadFile(c.opts.DataFile)
+		if err != nil {
+			return fmt.Errorf("failed to read data file: %w", err)
+		}
+		data = string(bytes)
+	}
+	c.requester.SetData(data)
+
+	// 请求中有占位符时替换占位符而不是追加路径
+	if err := c.setupPlaceholders(data); err != nil {
+		return err
+	}
+
 	// 设置认证
 	if c.opts.Auth != "" {
 		c.requester.SetAuth(c.opts.AuthType, c.opts.Auth)
@@ -92,29 +191,323 @@ func (c *Controller) Setup() error {
 		c.requester.SetProxyAuth(c.opts.ProxyAuth)
 	}
 
+	// 设置代理
+	if err := c.setupProxies(); err != nil {
+		return err
+	}
+
+	// 设置重放代理
+	if c.opts.ReplayProxy != "" {
+		replay, err := newReplayer(c.requester, c.opts.ReplayProxy, func(url string, err error) {
+			c.printer.Warning("Failed to replay %s: %s", url, err)
+		})
+		if err != nil {
+			return fmt.Errorf("invalid replay proxy: %w", err)
+		}
+		c.replay = replay
+	}
+
+	// 设置重定向
+	c.requester.SetFollowRedirects(c.opts.FollowRedirects)
+
+	// 指定服务器IP时直接连接该IP
+	c.requester.SetAddress(c.opts.IP)
+
+	return nil
+}
+
+// setupDictionary 加载字典，绑定到不同占位符的字典分别加载后组合
+func (c *Controller) setupDictionary() error {
+	var wordlists []parse.Wordlist
+	if c.opts.Wordlists != "" {
+		var err error
+		if wordlists, err = parse.ParseWordlists(c.opts.Wordlists); err != nil {
+			return fmt.Errorf("--wordlists: %w", err)
+		}
+	} else {
+		// 默认使用dict目录下的所有txt文件
+		for _, file := range utils.GetFilesByExtension("./dict", "txt") {
+			wordlists = append(wordlists, parse.Wordlist{Path: file})
+		}
+		if len(wordlists) == 0 {
+			return fmt.Errorf("no dictionary files found in dict directory")
+		}
+	}
+
+	// 按占位符分组，未绑定占位符的字典使用默认占位符
+	files := make(map[string][]string)
+	c.keywords = nil
+	c.dictFiles = nil
+	for _, wordlist := range wordlists {
+		keyword := wordlist.Keyword
+		if keyword == "" {
+			keyword = common.FUZZ_KEYWORD
+		}
+		if _, ok := files[keyword]; !ok {
+			c.keywords = append(c.keywords, keyword)
+		}
+		files[keyword] = append(files[keyword], wordlist.Path)
+
+		// 保存字典文件路径
+		c.dictFiles = append(c.dictFiles, wordlist.Path)
+	}
+
+	dictionaries := make([]*Dictionary, 0, len(c.keywords))
+	for _, keyword := range c.keywords {
+		dictionary := NewDictionary(files[keyword]...)
+		dictionary.SetOptions(c.opts)
+		if err := dictionary.Load(); err != nil {
+			return err
+		}
+		dictionaries = append(dictionaries, dictionary)
+	}
+
+	combinator, err := NewCombinator(c.keywords, dictionaries, c.opts.FuzzMode)
+	if err != nil {
+		return fmt.Errorf("--fuzz-mode: %w", err)
+	}
+	if len(dictionaries) == 1 {
+		c.dictionary = dictionaries[0]
+	} else {
+		c.dictionary = combinator
+	}
+
+	return nil
+}
+
+// setupPlaceholders 检查目标URL、请求头和请求数据中的占位符
+//
+// 只使用默认占位符且请求中没有出现时按目录扫描；否则每个字典绑定的占位符都必须出现在请求中。
+// 虚拟主机模式总是替换占位符。
+func (c *Controller) setupPlaceholders(data string) error {
+	templates := append([]string{data}, c.targets...)
+
+	// 虚拟主机模式下默认占位符位于目标的主机名中
+	if c.opts.Vhost {
+		if !slices.Contains(c.keywords, common.FUZZ_KEYWORD) {
+			return fmt.Errorf("--vhost: no wordlist bound to %s", common.FUZZ_KEYWORD)
+		}
+		templates = append(templates, common.FUZZ_KEYWORD)
+	}
+	add := func(headers map[string]string) {
+		for key, value := range headers {
+			templates = append(templates, key, value)
+		}
+	}
+	add(c.buildHeaders(nil))
+	for _, raw := range c.rawRequests {
+		add(c.buildHeaders(raw))
+		templates = append(templates, raw.Body)
+	}
+	content := strings.Join(templates, "\n")
+
+	var missing []string
+	for _, keyword := range c.keywords {
+		if !strings.Contains(content, keyword) {
+			missing = append(missing, keyword)
+		}
+	}
+	switch {
+	case len(missing) == 0:
+		c.template = true
+		c.requester.SetKeywords(c.keywords)
+		c.fuzzer.SetTemplate(true)
+	case len(c.keywords) == 1 && c.keywords[0] == common.FUZZ_KEYWORD:
+		c.template = false
+		c.fuzzer.SetTemplate(false)
+	default:
+		return fmt.Errorf("placeholder %s not found in the request", strings.Join(missing, ", "))
+	}
+
 	return nil
 }
 
-// setupCallbacks 设置回调函数
+// setupTransport 根据选项设置超时和TLS
+func (c *Controller) setupTransport() error {
+	minVersion, err := parse.ParseTLSVersion(c.opts.TLSMinVersion)
+	if err != nil {
+		return err
+	}
+
+	return c.requester.SetTransport(connection.TransportOptions{
+		Timeout:       time.Duration(c.opts.Timeout * float64(time.Second)),
+		ReadTimeout:   time.Duration(c.opts.ReadTimeout * float64(time.Second)),
+		CertFile:      c.opts.CertFile,
+		KeyFile:       c.opts.KeyFile,
+		CAFile:        c.opts.CAFile,
+		MinTLSVersion: minVersion,
+		ServerName:    c.opts.SNI,
+		VerifyTLS:     c.opts.VerifyTLS,
+	})
+}
+
+// setupProxies 根据选项建立代理池
+func (c *Controller) setupProxies() error {
+	proxies := append([]string{}, c.opts.Proxies...)
+	if c.opts.ProxyFile != "" {
+		bytes, err := os.ReadFile(c.opts.ProxyFile)
+		if err != nil {
+			return fmt.Errorf("failed to read proxy file: %w", err)
+		}
+		for _, line := range strings.Split(string(bytes), "\n") {
+			line = strings.TrimSpace(line)
+			if line != "" && !strings.HasPrefix(line, "#") {
+				proxies = append(proxies, line)
+			}
+		}
+	}
+	if c.opts.Tor {
+		proxies = common.DEFAULT_TOR_PROXIES
+	}
+	if len(proxies) == 0 {
+		return nil
+	}
+	// 代理会自行解析单词组成的主机名，无法连接到指定的服务器
+	if c.opts.Vhost {
+		return fmt.Errorf("--vhost cannot be used with proxies")
+	}
+
+	var random bool
+	switch c.opts.ProxyRotate {
+	case "", "round-robin":
+	case "random":
+		random = true
+	default:
+		return fmt.Errorf("invalid proxy rotation %q, expected round-robin or random", c.opts.ProxyRotate)
+	}
+
+	pool, err := connection.NewProxyPool(proxies, random)
+	if err != nil {
+		return err
+	}
+	pool.SetEvictCallback(func(proxy *url.URL, err error) {
+		c.printer.Warning("Proxy %s is not reachable, removed from the pool: %s", proxy.Redacted(), err)
+	})
+	c.requester.SetProxyPool(pool)
+
+	return nil
+}
+
+// setupFilters 根据选项建立响应过滤器
+func (c *Controller) setupFilters() error {
+	c.filters = make([]filter.Filter, 0)
+
+	// 状态码
+	if c.opts.IncludeStatusCodes != "" {
+		matcher, err := parse.ParseStatusCodes(c.opts.IncludeStatusCodes)
+		if err != nil {
+			return fmt.Errorf("--include-status: %w", err)
+		}
+		c.filters = append(c.filters, NewIncludeStatusFilter(matcher))
+	}
+	if c.opts.ExcludeStatusCodes != "" {
+		matcher, err := parse.ParseStatusCodes(c.opts.ExcludeStatusCodes)
+		if err != nil {
+			return fmt.Errorf("--exclude-status: %w", err)
+		}
+		c.filters = append(c.filters, NewExcludeStatusFilter(matcher))
+	}
+
+	// 响应大小
+	if c.opts.MinimumResponseSize > 0 || c.opts.MaximumResponseSize > 0 {
+		c.filters = append(c.filters, NewSizeRangeFilter(int64(c.opts.MinimumResponseSize), int64(c.opts.MaximumResponseSize)))
+	}
+	if c.opts.ExcludeSizes != "" {
+		matcher, err := parse.ParseSizes(c.opts.ExcludeSizes)
+		if err != nil {
+			return fmt.Errorf("--exclude-sizes: %w", err)
+		}
+		c.filters = append(c.filters, NewExcludeSizesFilter(matcher))
+	}
+
+	// 内容
+	if len(c.opts.ExcludeTexts) > 0 {
+		c.filters = append(c.filters, NewExcludeTextFilter(c.opts.ExcludeTexts...))
+	}
+	if c.opts.ExcludeRegex != "" {
+		regex, err := regexp.Compile(c.opts.ExcludeRegex)
+		if err != nil {
+			return fmt.Errorf("--exclude-regex: %w", err)
+		}
+		c.filters = append(c.filters, NewExcludeRegexFilter(regex))
+	}
+	if c.opts.ExcludeRedirect != "" {
+		regex, err := regexp.Compile(c.opts.ExcludeRedirect)
+		if err != nil {
+			return fmt.Errorf("--exclude-redirect: %w", err)
+		}
+		c.filters = append(c.filters, NewExcludeRedirectFilter(regex))
+	}
+
+	// 单词数、行数和标题
+	counts := []struct {
+		flag   string
+		value  string
+		match  bool
+		create func(*parse.RangeMatcher, bool) filter.Filter
+	}{
+		{"--match-words", c.opts.MatchWords, true, NewWordsFilter},
+		{"--filter-words", c.opts.FilterWords, false, NewWordsFilter},
+		{"--match-lines", c.opts.MatchLines, true, NewLinesFilter},
+		{"--filter-lines", c.opts.FilterLines, false, NewLinesFilter},
+	}
+	for _, count := range counts {
+		if count.value == "" {
+			continue
+		}
+		matcher, err := parse.ParseRanges(count.value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", count.flag, err)
+		}
+		c.filters = append(c.filters, count.create(matcher, count.match))
+	}
+	if c.opts.MatchTitle != "" {
+		c.filters = append(c.filters, NewTitleFilter(c.opts.MatchTitle, true))
+	}
+	if c.opts.FilterTitle != "" {
+		c.filters = append(c.filters, NewTitleFilter(c.opts.FilterTitle, false))
+	}
+
+	return nil
+}
+
+// AddFilter 添加自定义过滤器，在选项生成的过滤器之后执行
+func (c *Controller) AddFilter(f filter.Filter) {
+	c.customFilters = append(c.customFilters, f)
+}
+
+// buildFilterChain 为当前目标组装过滤器链
+func (c *Controller) buildFilterChain(excludeResponse *Comparer) *filter.Chain {
+	chain := filter.NewChain(c.filters...)
+	if excludeResponse != nil {
+		chain.Add(NewExcludeResponseFilter(excludeResponse))
+	}
+	return chain.Add(c.customFilters...)
+}
+
+// setupCallbacks 设置回调函数，回调由Fuzzer的结果分发协程依次调用，不会并发执行
 func (c *Controller) setupCallbacks() {
 	// 匹配回调
 	c.fuzzer.AddMatchCallback(func(response *connection.Response) {
 		c.matchCallback(response)
+		c.checkpoint()
 	})
 
 	// 未找到回调
-	c.fuzzer.AddNotFoundCallback(func(response *connection.Response) {
-		c.notFoundCallback(response)
+	c.fuzzer.AddNotFoundCallback(func(response *connection.Response, verdict filter.Verdict) {
+		c.notFoundCallback(response, verdict)
+		c.checkpoint()
 	})
 
 	// 错误回调
 	c.fuzzer.AddErrorCallback(func(err error) {
 		c.errorCallback(err)
+		c.checkpoint()
 	})
 }
 
 // processURLs 处理URLs
-func (c *Controller) processURLs() {
+func (c *Controller) processURLs() error {
 	if c.opts.URLFile != "" {
 		f := utils.NewFile(c.opts.URLFile)
 		c.targets = f.GetLines()
@@ -125,18 +518,52 @@ func (c *Controller) processURLs() {
 		bytes, _ := os.ReadFile("/dev/stdin")
 		c.targets = strings.Split(string(bytes), "\n")
 	} else if c.opts.RawFile != "" {
-		// 处理原始请求文件
-		// 这里简化实现
+		// 处理原始请求文件，每个请求一个目标
+		requests, err := c.loadRawRequests()
+		if err != nil {
+			return err
+		}
+		for _, request := range requests {
+			c.targets = append(c.targets, request.URL)
+		}
 	} else {
 		c.targets = c.opts.URLs
 	}
 
 	// 去重
 	c.targets = utils.Uniq(c.targets)
+
+	return nil
+}
+
+// loadRawRequests 读取原始请求文件，同一目标有多个请求时使用第一个
+func (c *Controller) loadRawRequests() ([]*parse.RawRequest, error) {
+	content, err := os.ReadFile(c.opts.RawFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read raw request file: %w", err)
+	}
+	requests, err := parse.ParseRawRequests(string(content), c.opts.Scheme)
+	if err != nil {
+		return nil, err
+	}
+
+	c.rawRequests = make(map[string]*parse.RawRequest)
+	for _, request := range requests {
+		if _, ok := c.rawRequests[request.URL]; !ok {
+			c.rawRequests[request.URL] = request
+		}
+	}
+
+	return requests, nil
 }
 
 // setupHeaders 设置请求头
 func (c *Controller) setupHeaders() {
+	c.requester.SetHeaders(c.buildHeaders(nil))
+}
+
+// buildHeaders 合并默认头、原始请求中的头和选项指定的头，后者优先
+func (c *Controller) buildHeaders(raw *parse.RawRequest) map[string]string {
 	// 合并默认头和自定义头
 	headers := make(map[string]string)
 
@@ -145,6 +572,16 @@ func (c *Controller) setupHeaders() {
 		headers[k] = v
 	}
 
+	// 添加原始请求中的头
+	if raw != nil {
+		for k, v := range raw.Headers {
+			headers[k] = v
+		}
+		if raw.Cookie != "" {
+			headers["Cookie"] = raw.Cookie
+		}
+	}
+
 	// 添加自定义头
 	if c.opts.HeaderFile != "" {
 		f := utils.NewFile(c.opts.HeaderFile)
@@ -175,17 +612,43 @@ func (c *Controller) setupHeaders() {
 		headers["Cookie"] = c.opts.Cookie
 	}
 
-	c.requester.SetHeaders(headers)
+	// 虚拟主机模式下Host头由请求URL决定
+	if c.opts.Vhost {
+		for key := range headers {
+			if strings.EqualFold(key, "Host") {
+				delete(headers, key)
+			}
+		}
+	}
+
+	return headers
+}
+
+// applyRawRequest 扫描来自原始请求文件的目标时使用该请求的方法、请求头和请求体，
+// 选项中指定的方法和数据优先
+func (c *Controller) applyRawRequest(target string) {
+	raw, ok := c.rawRequests[target]
+	if !ok {
+		return
+	}
+
+	if c.opts.HTTPMethod == "" {
+		c.requester.SetMethod(raw.Method)
+	}
+	if c.opts.Data == "" && c.opts.DataFile == "" {
+		c.requester.SetData(raw.Body)
+	}
+	c.requester.SetHeaders(c.buildHeaders(raw))
 }
 
 // Run 运行扫描
 func (c *Controller) Run() {
 	// 输出主要参数信息
-	fmt.Println("\n=== Scan Configuration ===")
-	fmt.Println("Target URLs:")
+	c.printer.Info("\n=== Scan Configuration ===\n")
+	c.printer.Info("Target URLs:\n")
 	for _, target := range c.targets {
 		if target != "" {
-			fmt.Printf("  - %s\n", target)
+			c.printer.Info("  - %s\n", target)
 		}
 	}
 	
@@ -194,90 +657,344 @@ func (c *Controller) Run() {
 	if c.opts.HTTPMethod != "" {
 		httpMethod = c.opts.HTTPMethod
 	}
-	fmt.Printf("HTTP Method: %s\n", httpMethod)
+	c.printer.Info("HTTP Method: %s\n", httpMethod)
 	
 	// 输出使用的字典文件
-	fmt.Println("Dictionary Files:")
+	c.printer.Info("Dictionary Files:\n")
 	for _, dictFile := range c.dictFiles {
-		fmt.Printf("  - %s\n", dictFile)
+		c.printer.Info("  - %s\n", dictFile)
 	}
-	fmt.Println("========================")
+	c.printer.Info("========================\n")
 
-	c.startTime = time.Now()
+	if c.session != nil {
+		c.printer.Info("Resuming session %s (target %d/%d, %d results)\n", c.opts.SessionFile, c.targetIndex+1, len(c.targets), len(c.results))
+	} else {
+		c.startTime = time.Now()
+	}
+	c.lastSave = time.Now()
 
-	for _, target := range c.targets {
+	// Ctrl+C时暂停扫描并显示菜单，SIGTERM时保存会话并退出
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer func() {
+		signal.Stop(signals)
+		close(signals)
+	}()
+	go func() {
+		for sig := range signals {
+			c.handleSignal(sig)
+		}
+	}()
+
+	// 显示进度
+	stopProgress := c.showProgress()
+	defer stopProgress()
+
+	// 报告在扫描过程中增量写入，路径包含目标占位符时每个目标单独生成一份报告
+	perTarget := report.HasTargetPlaceholder(c.opts.OutputFile)
+	if c.opts.OutputFile != "" && !perTarget {
+		c.openReport("", c.targets)
+	}
+
+	for ; c.targetIndex < len(c.targets); c.targetIndex++ {
+		target := c.targets[c.targetIndex]
+
+		// 开始新目标时保存会话，恢复时从该目标开始
+		if c.opts.SessionFile != "" && c.session == nil {
+			c.saveSession(c.opts.SessionFile)
+		}
+
+		if c.opts.OutputFile != "" && perTarget {
+			c.openReport(target, []string{target})
+		}
 		c.scanTarget(target)
+		if perTarget {
+			c.closeReport()
+		}
+		if c.interrupted.Load() {
+			break
+		}
+	}
+	if c.replay != nil {
+		if dropped := c.replay.close(); dropped > 0 {
+			c.printer.Warning("%d matching requests were not replayed because the replay queue was full", dropped)
+		}
+	}
+	c.closeReport()
+
+	if c.interrupted.Load() {
+		if c.discardSession.Load() {
+			c.printer.Warning("\nCanceled by the user")
+			return
+		}
+
+		path := c.opts.SessionFile
+		if path == "" {
+			path = filepath.Join(common.SESSIONS_DIRECTORY, "hidir-"+c.startTime.Format("2006-01-02_15-04-05")+".json")
+		}
+		if !c.saveSession(path) {
+			return
+		}
+		c.printer.Info("Session saved to %s, resume with: hidir --session %s\n", path, path)
+		return
+	}
+
+	// 扫描完成，不再需要会话文件
+	if c.opts.SessionFile != "" {
+		os.Remove(c.opts.SessionFile)
 	}
 
-	fmt.Println("\nTask Completed")
+	c.printer.Info("\nTask Completed\n")
 }
 
 // scanTarget 扫描单个目标
 func (c *Controller) scanTarget(target string) {
 	// 设置目标URL
-	c.requester.SetURL(target)
+	if c.opts.Vhost {
+		if !c.setupVhost(target) {
+			return
+		}
+	} else {
+		c.requester.SetURL(target)
+	}
+	c.applyRawRequest(target)
+	c.fuzzer.ResetScanners()
+
+	// 请求需要排除的参考页面
+	var excludeResponse *Comparer
+	if c.opts.ExcludeResponse != "" {
+		excludeResponse = c.setupExcludeResponse()
+	}
+	c.fuzzer.SetFilters(c.buildFilterChain(excludeResponse))
 
 	// 初始化目录
-	c.directories = make([]string, 0)
+	c.currentTarget = target
+	c.skipTarget.Store(false)
+	c.directories = &directoryQueue{}
+	c.progress.reset(target)
 
-	// 添加子目录
-	if c.opts.Subdirs != "" {
+	// 恢复会话中保存的目录队列和字典进度
+	var progress *FuzzerProgress
+	if c.session != nil && len(c.session.Directories) > 0 {
+		for _, dir := range c.session.Directories {
+			c.directories.push(dir.restore())
+		}
+		c.progress.setDirectories(c.directories.Len())
+		progress = &c.session.Progress
+	} else if c.opts.Subdirs != "" && !c.template {
+		// 添加子目录，替换占位符时不使用
 		subdirs := strings.Split(c.opts.Subdirs, ",")
 		for _, subdir := range subdirs {
-			c.addDirectory(subdir)
+			c.addDirectory(normalizeDirectory(subdir))
 		}
 	} else {
 		// 默认添加根目录
 		c.addDirectory("")
 	}
+	c.session = nil
 
-	// 开始扫描
-	for _, dir := range c.directories {
-		c.fuzzer.SetBasePath(dir)
+	// 开始扫描，扫描过程中递归发现的目录会加入队列
+	for !c.skipTarget.Load() {
+		dir, ok := c.directories.next()
+		if !ok {
+			break
+		}
+		c.currentDirectory = dir
+		c.fuzzer.SetBasePath(dir.Path)
+		var restored int64
+		if progress != nil {
+			c.fuzzer.Restore(*progress)
+			restored = int64(progress.Position + len(progress.Processed))
+			progress = nil
+		}
 		c.fuzzer.Start(c.opts.ThreadCount)
+		// 在目录之间选择了退出或跳过目标时不扫描下一个目录
+		if c.interrupted.Load() || c.skipTarget.Load() {
+			c.fuzzer.Stop()
+		}
+		c.progress.startDirectory(dir.Path, c.directories.index, restored)
 		c.fuzzer.Wait()
+		c.progress.finishDirectory(c.fuzzer.Stats())
+
+		// 中断时保留当前目录，以便保存会话
+		if c.interrupted.Load() {
+			return
+		}
 		c.dictionary.Reset()
 	}
+	c.currentDirectory = nil
 }
 
-// addDirectory 添加目录到扫描队列
+// setupVhost 虚拟主机模式下单词替换目标主机名，连接仍然发往指定的IP或目标本身
+func (c *Controller) setupVhost(target string) bool {
+	template, err := vhostURL(target)
+	if err != nil {
+		c.printer.Error("Skipping target: %s", err)
+		return false
+	}
+	c.requester.SetURL(template)
+
+	if c.opts.IP == "" {
+		parsed, _ := url.Parse(target)
+		c.requester.SetAddress(parsed.Hostname())
+	}
+	return true
+}
+
+// setupExcludeResponse 请求 --exclude-response 指定的页面作为比较基准
+func (c *Controller) setupExcludeResponse() *Comparer {
+	path := strings.TrimPrefix(c.opts.ExcludeResponse, "/")
+	response, err := c.requester.Request(path)
+	if err != nil {
+		c.printer.Warning("Failed to request exclude response page %s: %s\n", c.opts.ExcludeResponse, err)
+		return nil
+	}
+
+	return NewComparer(c.opts.SimilarityThreshold, ComparerSample{Response: response, Path: path})
+}
+
+// addDirectory 添加初始目录到扫描队列
 func (c *Controller) addDirectory(path string) {
+	c.enqueueDirectory(&directory{Path: path})
+}
+
+// enqueueDirectory 添加目录到扫描队列，返回是否已加入
+func (c *Controller) enqueueDirectory(dir *directory) bool {
 	// 检查是否在排除列表中
 	if c.opts.ExcludeSubdirs != "" {
 		excludeSubdirs := strings.Split(c.opts.ExcludeSubdirs, ",")
 		for _, exclude := range excludeSubdirs {
-			if strings.Contains(path, exclude) {
-				return
+			if containsSegments(dir.Path, exclude) {
+				return false
 			}
 		}
 	}
 
 	// 检查递归深度
-	if c.opts.RecursionDepth > 0 {
-		// 简化实现，实际应该更复杂
+	if c.opts.RecursionDepth > 0 && dir.Depth > c.opts.RecursionDepth {
+		return false
 	}
 
 	// 检查是否已处理
-	if _, ok := c.passedURLs[path]; ok {
-		return
+	url := c.currentTarget + dir.Path
+	if _, ok := c.passedURLs[url]; ok {
+		return false
+	}
+
+	c.directories.push(dir)
+	c.passedURLs[url] = true
+	c.progress.setDirectories(c.directories.Len())
+
+	return true
+}
+
+// recur 根据匹配的路径添加递归目录
+func (c *Controller) recur(path string, source *connection.Response) {
+	path = cleanRequestPath(path)
+
+	// 强制递归时所有路径都视为目录
+	if c.opts.ForceRecursive && !strings.HasSuffix(path, "/") {
+		path += "/"
+	}
+
+	if c.opts.DeepRecursive {
+		// 添加路径的每一级父目录
+		for i := 0; i < len(path); i++ {
+			if path[i] == '/' {
+				c.enqueueChild(path[:i+1], source)
+			}
+		}
+	} else if c.opts.ForceRecursive {
+		c.enqueueChild(path, source)
+	} else if strings.HasSuffix(path, "/") && !extensionRegex.MatchString(strings.TrimSuffix(path, "/")) {
+		// 带扩展名的路径通常不是目录
+		c.enqueueChild(path, source)
+	}
+}
+
+// enqueueChild 添加在当前目录下发现的子目录
+func (c *Controller) enqueueChild(path string, source *connection.Response) {
+	parent := c.currentDirectory
+	depth := strings.Count(path, "/")
+	if parent != nil {
+		// 当前目录之上的路径不是新发现的子目录
+		if !strings.HasPrefix(path, parent.Path) || path == parent.Path {
+			return
+		}
+		depth = parent.Depth + strings.Count(path[len(parent.Path):], "/")
+	}
+
+	c.enqueueDirectory(&directory{
+		Path:   path,
+		Depth:  depth,
+		Parent: parent,
+		Source: source,
+	})
+}
+
+// recurForResponse 处理匹配响应的递归，重定向到"路径/"时视为目录
+func (c *Controller) recurForResponse(response *connection.Response) {
+	path := cleanRequestPath(response.Path)
+
+	switch {
+	case response.Redirect != "":
+		redirect := cleanRequestPath(parse.ParsePath(response.Redirect))
+		if redirect == path+"/" || strings.HasSuffix(redirect, "/"+path+"/") {
+			c.recur(path+"/", response)
+		} else if c.opts.ForceRecursive || c.opts.DeepRecursive {
+			c.recur(path, response)
+		}
+	default:
+		c.recur(path, response)
+	}
+}
+
+// cleanRequestPath 去掉路径开头的"/"以及查询和片段
+func cleanRequestPath(path string) string {
+	path = strings.SplitN(strings.SplitN(path, "?", 2)[0], "#", 2)[0]
+	return strings.TrimLeft(path, "/")
+}
+
+// checkSkipStatus 响应状态码命中 --skip-on-status 时跳过当前目标
+func (c *Controller) checkSkipStatus(response *connection.Response) bool {
+	if c.skipStatus == nil || c.skipStatus.IsEmpty() || !c.skipStatus.Match(response.Status) {
+		return false
+	}
+
+	if !c.skipTarget.Swap(true) {
+		c.printer.Warning("Skipped the target due to %d status code\n", response.Status)
+		c.fuzzer.Stop()
 	}
 
-	c.directories = append(c.directories, path)
-	c.passedURLs[path] = true
+	return true
 }
 
 // matchCallback 匹配回调
 func (c *Controller) matchCallback(response *connection.Response) {
+	if c.checkSkipStatus(response) {
+		return
+	}
+
 	// 输出结果
-	fmt.Printf("[%d] %s\n", response.Status, response.FullPath)
+	c.printer.Result(response)
+
+	// 通过重放代理重新发送
+	if c.replay != nil && response.Request != nil {
+		c.replay.add(response.Request)
+	}
 
-	// 添加到结果
-	c.results = append(c.results, response)
+	// 添加到结果并写入报告
+	result := c.newResult(response)
+	c.results = append(c.results, result)
+	if c.reportOutput != nil {
+		if err := c.reportOutput.Add(result); err != nil {
+			c.printer.Error("Error writing report: %s", err)
+		}
+	}
 
-	// 处理递归
-	if c.opts.Recursive || c.opts.DeepRecursive || c.opts.ForceRecursive {
-		// 简化实现，实际应该更复杂
-		c.addDirectory(response.Path)
+	// 处理递归，替换占位符时不递归
+	if !c.template && (c.opts.Recursive || c.opts.DeepRecursive || c.opts.ForceRecursive) && c.recursionStatus.Match(response.Status) {
+		c.recurForResponse(response)
 	}
 
 	// 重置连续错误计数
@@ -285,8 +1002,14 @@ func (c *Controller) matchCallback(response *connection.Response) {
 }
 
 // notFoundCallback 未找到回调
-func (c *Controller) notFoundCallback(response *connection.Response) {
-	// 简化实现，实际应该更新进度条
+func (c *Controller) notFoundCallback(response *connection.Response, verdict filter.Verdict) {
+	c.checkSkipStatus(response)
+
+	// 输出丢弃原因以便排查
+	if c.opts.Verbose {
+		c.printer.Filtered(response, verdict.String())
+	}
+
 	c.consecutiveErrors = 0
 }
 
@@ -297,7 +1020,246 @@ func (c *Controller) errorCallback(err error) {
 
 	// 检查连续错误数
 	if c.consecutiveErrors > common.MAX_CONSECUTIVE_REQUEST_ERRORS {
-		fmt.Println("Too many consecutive errors, skipping target")
+		c.printer.Warning("Too many consecutive errors, skipping target")
+		c.skipTarget.Store(true)
 		c.fuzzer.Stop()
 	}
 }
+
+// newResult 将响应转换为报告结果
+func (c *Controller) newResult(response *connection.Response) *report.Result {
+	result := &report.Result{
+		Target:      c.currentTarget,
+		URL:         response.FullPath,
+		Path:        response.Path,
+		Status:      response.Status,
+		Size:        response.Length,
+		ContentType: response.ContentType,
+		Redirect:    response.Redirect,
+		Words:       response.Words,
+		Lines:       response.Lines,
+		Title:       response.Title,
+		BodyHash:    response.BodyHash,
+		Time:        time.Now(),
+	}
+	if c.currentDirectory != nil {
+		result.Depth = c.currentDirectory.Depth
+		if c.currentDirectory.Source != nil {
+			result.Source = c.currentDirectory.Source.FullPath
+		}
+	}
+	return result
+}
+
+// reportInfo 生成报告的扫描元数据
+func (c *Controller) reportInfo(targets []string, endTime time.Time) *report.Info {
+	method := c.opts.HTTPMethod
+	if method == "" {
+		method = "GET"
+	}
+
+	return &report.Info{
+		Version:   common.VERSION,
+		Command:   report.RedactCommand(os.Args),
+		Targets:   targets,
+		Wordlists: c.dictFiles,
+		Method:    method,
+		StartTime: c.startTime,
+		EndTime:   endTime,
+		Options:   report.OptionsMap(c.opts),
+	}
+}
+
+// openReport 创建报告文件或数据库记录，target为空时报告包含所有目标
+func (c *Controller) openReport(target string, targets []string) {
+	path := report.ExpandPath(c.opts.OutputFile, target, c.reportFormat, c.startTime)
+	output, err := report.Open(path, c.reportFormat, c.reportInfo(targets, time.Time{}))
+	if err != nil {
+		c.printer.Error("Error writing report: %s", err)
+		return
+	}
+	c.reportOutput = output
+
+	// 恢复会话时先写入之前的结果
+	for _, result := range c.results {
+		if target != "" && result.Target != target {
+			continue
+		}
+		if err := output.Add(result); err != nil {
+			c.printer.Error("Error writing report: %s", err)
+			return
+		}
+	}
+}
+
+// showProgress 定期刷新状态行，返回停止刷新的函数
+func (c *Controller) showProgress() func() {
+	stop := make(chan struct{})
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(200 * time.Millisecond)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				c.console.SetStatus(c.progress.format(c.fuzzer.Stats(), c.dictionary.Len()))
+			}
+		}
+	}()
+
+	return func() {
+		close(stop)
+		<-done
+		c.console.ClearStatus()
+	}
+}
+
+// handleSignal 处理Ctrl+C和SIGTERM
+//
+// 第一次Ctrl+C显示暂停菜单；菜单中再次中断或收到SIGTERM时停止扫描，
+// Run在已发出的请求完成后关闭报告并保存会话。正在退出时忽略后续的信号。
+func (c *Controller) handleSignal(sig os.Signal) {
+	switch {
+	case c.interrupted.Load():
+		c.printer.Warning("\nStill waiting for in-flight requests to finish...")
+	case sig == syscall.SIGTERM:
+		c.printer.Warning("\nTerminated, saving session...")
+		c.Interrupt()
+	case c.menuOpen.Load():
+		c.printer.Warning("\nInterrupted, saving session...")
+		c.Interrupt()
+	default:
+		go c.pauseMenu()
+	}
+}
+
+// Interrupt 停止扫描，Run在已发出的请求完成后保存会话并返回
+func (c *Controller) Interrupt() {
+	c.interrupted.Store(true)
+	c.fuzzer.Stop()
+}
+
+// pauseMenu 暂停扫描，等待已发出的请求完成后让用户选择如何继续
+func (c *Controller) pauseMenu() {
+	if c.menuOpen.Swap(true) {
+		return
+	}
+	defer c.menuOpen.Store(false)
+
+	c.console.HideStatus()
+	defer c.console.ShowStatus()
+
+	c.console.Println("\nPausing, waiting for in-flight requests to finish...")
+	c.fuzzer.Pause()
+	if !c.fuzzer.Drain(common.PAUSING_WAIT_TIMEOUT * time.Second) {
+		c.console.Printf("Some requests are still running after %d seconds\n", common.PAUSING_WAIT_TIMEOUT)
+	}
+
+	for {
+		c.console.Printf("[q]uit / [c]ontinue / [n]ext directory / [N]ext target / [s]ave session and quit: ")
+		line, err := c.input.ReadString('\n')
+		choice := strings.TrimSpace(line)
+		if err != nil && choice == "" {
+			// 无法读取选择时保存会话并退出
+			c.console.Println()
+			choice = "s"
+		}
+
+		switch choice {
+		case "c":
+			c.fuzzer.Resume()
+		case "n":
+			// 停止当前目录，继续扫描队列中的下一个目录
+			c.fuzzer.Stop()
+		case "N":
+			c.skipTarget.Store(true)
+			c.fuzzer.Stop()
+		case "s":
+			c.Interrupt()
+		case "q":
+			c.discardSession.Store(true)
+			c.Interrupt()
+		default:
+			continue
+		}
+		return
+	}
+}
+
+// restoreSession 恢复会话中保存的目标、结果和错误计数
+func (c *Controller) restoreSession() {
+	c.targets = c.session.Targets
+	c.targetIndex = c.session.TargetIndex
+	c.startTime = c.session.StartTime
+	c.results = append(c.results, c.session.Results...)
+	for _, url := range c.session.PassedURLs {
+		c.passedURLs[url] = true
+	}
+	c.errors = c.session.Errors
+	c.consecutiveErrors = c.session.ConsecutiveErrors
+}
+
+// snapshot 生成当前扫描状态的会话，只能在回调中或者扫描暂停在目录之间时调用
+func (c *Controller) snapshot() *Session {
+	session := &Session{
+		StartTime:         c.startTime,
+		Options:           c.opts,
+		Targets:           c.targets,
+		TargetIndex:       c.targetIndex,
+		Directories:       make([]SessionDirectory, 0),
+		PassedURLs:        make([]string, 0, len(c.passedURLs)),
+		Results:           c.results,
+		Errors:            c.errors,
+		ConsecutiveErrors: c.consecutiveErrors,
+	}
+
+	if c.currentDirectory != nil {
+		session.Directories = append(session.Directories, newSessionDirectory(c.currentDirectory))
+		session.Progress = c.fuzzer.Progress()
+		for _, dir := range c.directories.items[c.directories.index:] {
+			session.Directories = append(session.Directories, newSessionDirectory(dir))
+		}
+	}
+	for url := range c.passedURLs {
+		session.PassedURLs = append(session.PassedURLs, url)
+	}
+	sort.Strings(session.PassedURLs)
+
+	return session
+}
+
+// checkpoint 定期保存会话，在回调中调用
+func (c *Controller) checkpoint() {
+	if c.opts.SessionFile == "" || time.Since(c.lastSave) < common.SESSION_SAVE_INTERVAL*time.Second {
+		return
+	}
+	c.saveSession(c.opts.SessionFile)
+}
+
+// saveSession 保存会话，返回是否成功
+func (c *Controller) saveSession(path string) bool {
+	c.lastSave = time.Now()
+	if err := c.snapshot().Save(path); err != nil {
+		c.printer.Error("Error: %s", err)
+		return false
+	}
+	return true
+}
+
+// closeReport 写入报告结尾并关闭报告
+func (c *Controller) closeReport() {
+	if c.reportOutput == nil {
+		return
+	}
+	if err := c.reportOutput.Close(time.Now()); err != nil {
+		c.printer.Error("Error writing report: %s", err)
+	} else {
+		c.printer.Info("Report saved to %s\n", c.reportOutput.Path())
+	}
+	c.reportOutput = nil
+}
@@ -1,8 +1,23 @@
 package core
 
 import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"HiDir/internal/connection"
 	"HiDir/internal/parse"
 )
 
@@ -53,6 +68,20 @@ func TestControllerSetup(t *testing.T) {
 	})
 }
 
+func TestControllerSetupInvalidStatus(t *testing.T) {
+	for _, opts := range []*parse.Options{
+		{URLs: []string{"https://example.com"}, IncludeStatusCodes: "2xx,abc"},
+		{URLs: []string{"https://example.com"}, ExcludeStatusCodes: "500-400"},
+		{URLs: []string{"https://example.com"}, RecursionStatusCodes: "1000"},
+		{URLs: []string{"https://example.com"}, SkipOnStatus: "4x4"},
+	} {
+		controller := NewController(opts)
+		if err := controller.Setup(); err == nil {
+			t.Errorf("Expected error for options %+v", opts)
+		}
+	}
+}
+
 func TestControllerProcessURLs(t *testing.T) {
 	// 测试用例1：从URLs处理
 	t.Run("ProcessURLsFromURLs", func(t *testing.T) {
@@ -97,11 +126,11 @@ func TestControllerAddDirectory(t *testing.T) {
 		controller := NewController(opts)
 		controller.addDirectory("test")
 
-		if len(controller.directories) != 1 {
-			t.Errorf("Expected 1 directory, got %d", len(controller.directories))
+		if controller.directories.Len() != 1 {
+			t.Errorf("Expected 1 directory, got %d", controller.directories.Len())
 		}
-		if controller.directories[0] != "test" {
-			t.Errorf("Expected directory 'test', got '%s'", controller.directories[0])
+		if controller.directories.items[0].Path != "test" {
+			t.Errorf("Expected directory 'test', got '%s'", controller.directories.items[0].Path)
 		}
 	})
 
@@ -116,8 +145,8 @@ func TestControllerAddDirectory(t *testing.T) {
 		controller := NewController(opts)
 		controller.addDirectory("test")
 
-		if len(controller.directories) != 0 {
-			t.Errorf("Expected 0 directories for excluded directory, got %d", len(controller.directories))
+		if controller.directories.Len() != 0 {
+			t.Errorf("Expected 0 directories for excluded directory, got %d", controller.directories.Len())
 		}
 	})
 
@@ -132,8 +161,547 @@ func TestControllerAddDirectory(t *testing.T) {
 		controller.addDirectory("test")
 		controller.addDirectory("test")
 
-		if len(controller.directories) != 1 {
-			t.Errorf("Expected 1 directory for duplicate addition, got %d", len(controller.directories))
+		if controller.directories.Len() != 1 {
+			t.Errorf("Expected 1 directory for duplicate addition, got %d", controller.directories.Len())
+		}
+	})
+}
+
+func TestControllerRecursion(t *testing.T) {
+	paths := func(controller *Controller) []string {
+		var result []string
+		for _, dir := range controller.directories.items {
+			result = append(result, fmt.Sprintf("%s:%d", dir.Path, dir.Depth))
+		}
+		return result
+	}
+
+	tests := []struct {
+		name      string
+		opts      parse.Options
+		responses []*connection.Response
+		expected  []string
+	}{
+		{
+			name:      "RecursiveOnlyDirectories",
+			opts:      parse.Options{Recursive: true},
+			responses: []*connection.Response{{Status: 200, Path: "admin/"}, {Status: 200, Path: "login.php"}, {Status: 200, Path: "backup.old/"}},
+			expected:  []string{":0", "admin/:1"},
+		},
+		{
+			name:      "RedirectToDirectory",
+			opts:      parse.Options{Recursive: true},
+			responses: []*connection.Response{{Status: 301, Path: "images", Redirect: "http://example.com/images/"}},
+			expected:  []string{":0", "images/:1"},
+		},
+		{
+			name:      "ForceRecursive",
+			opts:      parse.Options{ForceRecursive: true},
+			responses: []*connection.Response{{Status: 200, Path: "admin"}, {Status: 200, Path: "login.php"}},
+			expected:  []string{":0", "admin/:1", "login.php/:1"},
+		},
+		{
+			name:      "DeepRecursive",
+			opts:      parse.Options{DeepRecursive: true},
+			responses: []*connection.Response{{Status: 200, Path: "a/b/c.php"}},
+			expected:  []string{":0", "a/:1", "a/b/:2"},
+		},
+		{
+			name:      "MaxRecursionDepth",
+			opts:      parse.Options{DeepRecursive: true, RecursionDepth: 1},
+			responses: []*connection.Response{{Status: 200, Path: "a/b/c/"}},
+			expected:  []string{":0", "a/:1"},
+		},
+		{
+			name:      "RecursionStatus",
+			opts:      parse.Options{Recursive: true, RecursionStatusCodes: "2xx,!204"},
+			responses: []*connection.Response{{Status: 403, Path: "private/"}, {Status: 200, Path: "public/"}},
+			expected:  []string{":0", "public/:1"},
+		},
+		{
+			name:      "ExcludeSubdirsBySegment",
+			opts:      parse.Options{Recursive: true, ExcludeSubdirs: "admin"},
+			responses: []*connection.Response{{Status: 200, Path: "admin/"}, {Status: 200, Path: "administrator/"}},
+			expected:  []string{":0", "administrator/:1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			controller := NewController(&tt.opts)
+			recursionStatus, err := parse.ParseStatusCodes(tt.opts.RecursionStatusCodes)
+			if err != nil {
+				t.Fatal(err)
+			}
+			controller.recursionStatus = recursionStatus
+
+			controller.addDirectory("")
+			controller.currentDirectory, _ = controller.directories.next()
+			for _, response := range tt.responses {
+				controller.matchCallback(response)
+			}
+
+			if got := paths(controller); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestControllerPauseMenu(t *testing.T) {
+	const total = 200
+
+	dir := t.TempDir()
+	var lines []string
+	for i := 0; i < total; i++ {
+		lines = append(lines, fmt.Sprintf("word-%d", i))
+	}
+	wordlist := filepath.Join(dir, "wordlist.txt")
+	if err := os.WriteFile(wordlist, []byte(strings.Join(lines, "\n")), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		input   string // 菜单中的输入
+		all     bool   // 是否请求了所有单词
+		session int    // 会话中保存的目录数，-1表示扫描完成后会话文件已删除
+	}{
+		{name: "Continue", input: "x\nc\n", all: true, session: -1},
+		{name: "NextTarget", input: "N\n", session: -1},
+		{name: "SaveAndQuit", input: "s\n", session: 1},
+		// 退出时不保存，只保留开始扫描目标时保存的会话
+		{name: "Quit", input: "q\n", session: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				mutex      sync.Mutex
+				requested  = make(map[string]int)
+				controller *Controller
+			)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				path := strings.TrimPrefix(r.URL.Path, "/")
+				mutex.Lock()
+				defer mutex.Unlock()
+				if strings.HasPrefix(path, "word-") {
+					requested[path]++
+					// 扫描到一半时模拟Ctrl+C
+					if len(requested) == total/2 {
+						go controller.pauseMenu()
+					}
+				}
+				http.NotFound(w, r)
+			}))
+			defer server.Close()
+
+			sessionFile := filepath.Join(t.TempDir(), "scan.session")
+			controller = NewController(&parse.Options{URLs: []string{server.URL + "/"}, Wordlists: wordlist, ThreadCount: 4, SessionFile: sessionFile})
+			controller.input = bufio.NewReader(strings.NewReader(tt.input))
+			if err := controller.Setup(); err != nil {
+				t.Fatal(err)
+			}
+			controller.Run()
+
+			mutex.Lock()
+			defer mutex.Unlock()
+			if all := len(requested) == total; all != tt.all {
+				t.Errorf("Expected all words requested to be %v, got %d of %d", tt.all, len(requested), total)
+			}
+			for path, count := range requested {
+				if count != 1 {
+					t.Errorf("Expected %s to be requested once, got %d", path, count)
+				}
+			}
+			session, err := LoadSession(sessionFile)
+			switch {
+			case tt.session < 0 && !os.IsNotExist(err):
+				t.Errorf("Expected session file to be removed, got %v", err)
+			case tt.session >= 0 && err != nil:
+				t.Errorf("Expected session file, got %v", err)
+			case tt.session >= 0 && len(session.Directories) != tt.session:
+				t.Errorf("Expected %d directories in session, got %d", tt.session, len(session.Directories))
+			}
+		})
+	}
+}
+
+func TestControllerSecondInterrupt(t *testing.T) {
+	const total = 200
+
+	dir := t.TempDir()
+	var lines []string
+	for i := 0; i < total; i++ {
+		lines = append(lines, fmt.Sprintf("word-%d", i))
+	}
+	wordlist := filepath.Join(dir, "wordlist.txt")
+	if err := os.WriteFile(wordlist, []byte(strings.Join(lines, "\n")), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		once       sync.Once
+		controller *Controller
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/word-1") {
+			// 第一次Ctrl+C打开菜单，菜单中再次Ctrl+C
+			once.Do(func() {
+				go func() {
+					controller.handleSignal(os.Interrupt)
+					for !controller.menuOpen.Load() {
+						time.Sleep(10 * time.Millisecond)
+					}
+					controller.handleSignal(os.Interrupt)
+				}()
+			})
+		}
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	sessionFile := filepath.Join(dir, "scan.session")
+	outputFile := filepath.Join(dir, "report.json")
+	controller = NewController(&parse.Options{URLs: []string{server.URL + "/"}, Wordlists: wordlist, ThreadCount: 4, SessionFile: sessionFile, OutputFile: outputFile})
+	// 菜单等待的输入永远不会到达
+	input, _ := io.Pipe()
+	controller.input = bufio.NewReader(input)
+	if err := controller.Setup(); err != nil {
+		t.Fatal(err)
+	}
+
+	finished := make(chan struct{})
+	go func() {
+		controller.Run()
+		close(finished)
+	}()
+	select {
+	case <-finished:
+	case <-time.After(10 * time.Second):
+		t.Fatal("Expected Run to return after the second interrupt")
+	}
+
+	// 报告写入了结尾，会话已保存
+	data, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Errorf("Expected a complete JSON report, got %v", err)
+	}
+	if _, err := LoadSession(sessionFile); err != nil {
+		t.Errorf("Expected session to be saved, got %v", err)
+	}
+}
+
+func TestControllerProgress(t *testing.T) {
+	// 只有带%EXT%的行按扩展名生成多个单词，共 5×6+10 个
+	const total = 40
+
+	dir := t.TempDir()
+	var lines []string
+	for i := 0; i < 5; i++ {
+		lines = append(lines, fmt.Sprintf("index-%d.%%EXT%%", i))
+	}
+	for i := 0; i < 10; i++ {
+		lines = append(lines, fmt.Sprintf("word-%d", i))
+	}
+	wordlist := filepath.Join(dir, "wordlist.txt")
+	if err := os.WriteFile(wordlist, []byte(strings.Join(lines, "\n")), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// 扫描持续多个刷新周期，字典在状态行刷新期间用完，需配合 -race 运行
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(10 * time.Millisecond)
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	controller := NewController(&parse.Options{URLs: []string{server.URL + "/"}, Wordlists: wordlist, Extensions: "php,asp,aspx,jsp,html,js", ThreadCount: 1})
+	if err := controller.Setup(); err != nil {
+		t.Fatal(err)
+	}
+	if got := controller.dictionary.Len(); got != total {
+		t.Errorf("Expected dictionary length %d before the scan, got %d", total, got)
+	}
+	controller.Run()
+
+	status := controller.progress.format(controller.fuzzer.Stats(), controller.dictionary.Len())
+	if !strings.Contains(status, "100%") || !strings.Contains(status, fmt.Sprintf("%d/%d", total, total)) {
+		t.Errorf("Expected the finished scan to show %d/%d, got %q", total, total, status)
+	}
+}
+
+func TestControllerRawRequest(t *testing.T) {
+	var (
+		mutex    sync.Mutex
+		requests []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mutex.Lock()
+		requests = append(requests, fmt.Sprintf("%s %s %s %s %s", r.Method, r.URL.RequestURI(), r.Header.Get("Cookie"), r.Header.Get("X-Api-Key"), body))
+		mutex.Unlock()
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	wordlist := filepath.Join(dir, "wordlist.txt")
+	if err := os.WriteFile(wordlist, []byte("users\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	raw := "POST /api/?debug=1 HTTP/1.1\r\n" +
+		"Host: " + strings.TrimPrefix(server.URL, "http://") + "\r\n" +
+		"Cookie: session=abc\r\n" +
+		"X-Api-Key: secret\r\n" +
+		"Content-Length: 6\r\n" +
+		"\r\n" +
+		"id=123"
+	rawFile := filepath.Join(dir, "request.txt")
+	if err := os.WriteFile(rawFile, []byte(raw), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	controller := NewController(&parse.Options{RawFile: rawFile, Scheme: "http", Wordlists: wordlist})
+	if err := controller.Setup(); err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{server.URL + "/api/?debug=1"}; !reflect.DeepEqual(controller.targets, expected) {
+		t.Errorf("Expected targets %v, got %v", expected, controller.targets)
+	}
+	controller.Run()
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	expected := "POST /api/users?debug=1 session=abc secret id=123"
+	found := false
+	for _, request := range requests {
+		found = found || request == expected
+	}
+	if !found {
+		t.Errorf("Expected request %q, got %v", expected, requests)
+	}
+}
+
+func TestControllerPlaceholders(t *testing.T) {
+	dir := t.TempDir()
+	writeWordlist := func(name string, words ...string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(strings.Join(words, "\n")), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	ids := writeWordlist("ids.txt", "1", "7", "42")
+	users := writeWordlist("users.txt", "admin", "guest")
+	passwords := writeWordlist("passwords.txt", "secret", "123456")
+	terms := writeWordlist("terms.txt", "www", "foo", "admin", "_hidden")
+
+	var (
+		mutex    sync.Mutex
+		requests []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		request := fmt.Sprintf("%s %s %s %s", r.Host, r.URL.RequestURI(), r.Header.Get("X-User"), body)
+		mutex.Lock()
+		requests = append(requests, request)
+		mutex.Unlock()
+
+		// 只有特定的参数返回不同的内容，其余请求的响应相同
+		switch {
+		case r.URL.Query().Get("id") == "42", r.Header.Get("X-User") == "admin" && string(body) == "password=secret":
+			w.Write([]byte("found"))
+		case r.URL.Path == "/search" && r.URL.Query().Get("q") != "admin":
+			// 反射查询参数的搜索页面
+			q := r.URL.Query().Get("q")
+			fmt.Fprintf(w, "No results for q=%s, try %s.example.com", q, q)
+		default:
+			w.Write([]byte("nothing here"))
+		}
+	}))
+	defer server.Close()
+
+	// 查询字符串中带有占位符的原始请求
+	rawFile := filepath.Join(dir, "request.txt")
+	raw := "GET /item?id=FUZZ HTTP/1.1\r\nHost: " + strings.TrimPrefix(server.URL, "http://") + "\r\n\r\n"
+	if err := os.WriteFile(rawFile, []byte(raw), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		opts     parse.Options
+		matches  []string // 匹配结果的Payload
+		requests []string // 必须发出的请求
+	}{
+		{
+			name:     "QueryString",
+			opts:     parse.Options{URLs: []string{server.URL + "/item?id=FUZZ"}, Wordlists: ids},
+			matches:  []string{"FUZZ=42"},
+			requests: []string{"/item?id=1", "/item?id=7", "/item?id=42"},
+		},
+		{
+			name:     "RawRequestQuery",
+			opts:     parse.Options{RawFile: rawFile, Scheme: "http", Wordlists: ids},
+			matches:  []string{"FUZZ=42"},
+			requests: []string{"/item?id=1", "/item?id=42"},
+		},
+		{
+			name:     "ReflectedValue",
+			opts:     parse.Options{URLs: []string{server.URL + "/search?q=FUZZ"}, Wordlists: terms},
+			matches:  []string{"FUZZ=admin"},
+			requests: []string{"/search?q=www", "/search?q=_hidden"},
+		},
+		{
+			name: "HeaderAndBodyPitchfork",
+			opts: parse.Options{
+				URLs:       []string{server.URL + "/login"},
+				Wordlists:  users + ":USER," + passwords + ":PASS",
+				FuzzMode:   FuzzModePitchfork,
+				HTTPMethod: "POST",
+				Headers:    []string{"X-User: USER"},
+				Data:       "password=PASS",
+			},
+			matches:  []string{"PASS=secret, USER=admin"},
+			requests: []string{"/login admin password=secret", "/login guest password=123456"},
+		},
+		{
+			name: "HeaderAndBodyClusterbomb",
+			opts: parse.Options{
+				URLs:       []string{server.URL + "/login"},
+				Wordlists:  users + ":USER," + passwords + ":PASS",
+				HTTPMethod: "POST",
+				Headers:    []string{"X-User: USER"},
+				Data:       "password=PASS",
+			},
+			matches:  []string{"PASS=secret, USER=admin"},
+			requests: []string{"/login admin password=secret", "/login admin password=123456", "/login guest password=secret", "/login guest password=123456"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mutex.Lock()
+			requests = nil
+			mutex.Unlock()
+
+			var matches []string
+			controller := NewController(&tt.opts)
+			if err := controller.Setup(); err != nil {
+				t.Fatal(err)
+			}
+			controller.fuzzer.AddMatchCallback(func(response *connection.Response) {
+				matches = append(matches, response.Payload)
+			})
+			controller.Run()
+
+			if !reflect.DeepEqual(matches, tt.matches) {
+				t.Errorf("Expected matches %v, got %v", tt.matches, matches)
+			}
+			mutex.Lock()
+			defer mutex.Unlock()
+			for _, expected := range tt.requests {
+				found := false
+				for _, request := range requests {
+					found = found || strings.Contains(request, expected)
+				}
+				if !found {
+					t.Errorf("Expected request %q, got %v", expected, requests)
+				}
+			}
+		})
+	}
+
+	// 字典绑定的占位符没有出现在请求中
+	t.Run("MissingPlaceholder", func(t *testing.T) {
+		controller := NewController(&parse.Options{URLs: []string{server.URL + "/?user=USER"}, Wordlists: users + ":USER," + passwords + ":PASS"})
+		if err := controller.Setup(); err == nil || !strings.Contains(err.Error(), "PASS") {
+			t.Errorf("Expected error for missing placeholder PASS, got %v", err)
+		}
+	})
+}
+
+func TestControllerVhost(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host, _, _ := net.SplitHostPort(r.Host)
+		switch host {
+		case "admin.example.test":
+			w.Write([]byte("admin panel"))
+		case "dev.example.test":
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte("forbidden"))
+		default:
+			// 默认虚拟主机反射请求的Host头
+			fmt.Fprintf(w, "welcome to %s, this is the default site", r.Host)
+		}
+	}))
+	defer server.Close()
+	_, port, _ := net.SplitHostPort(strings.TrimPrefix(server.URL, "http://"))
+
+	dir := t.TempDir()
+	writeWordlist := func(name string, words ...string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(strings.Join(words, "\n")), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	hostnames := writeWordlist("hostnames.txt", "admin.example.test", "www.example.test", "dev.example.test", "foo.example.test")
+	subdomains := writeWordlist("subdomains.txt", "admin", "www", "dev", "foo")
+
+	tests := []struct {
+		name    string
+		opts    parse.Options
+		matches []string
+	}{
+		{
+			// 测试用例1：目标为IP，单词为完整的主机名
+			name:    "IPTarget",
+			opts:    parse.Options{URLs: []string{server.URL + "/"}, Wordlists: hostnames, Vhost: true},
+			matches: []string{"http://admin.example.test:" + port + "/", "http://dev.example.test:" + port + "/"},
+		},
+		{
+			// 测试用例2：目标为域名，单词作为子域名，连接--ip指定的服务器
+			name:    "DomainWithIP",
+			opts:    parse.Options{URLs: []string{"http://example.test:" + port + "/"}, Wordlists: subdomains, Vhost: true, IP: "127.0.0.1"},
+			matches: []string{"http://admin.example.test:" + port + "/", "http://dev.example.test:" + port + "/"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				mutex   sync.Mutex
+				matches []string
+			)
+			controller := NewController(&tt.opts)
+			if err := controller.Setup(); err != nil {
+				t.Fatal(err)
+			}
+			controller.fuzzer.AddMatchCallback(func(response *connection.Response) {
+				mutex.Lock()
+				defer mutex.Unlock()
+				matches = append(matches, response.FullPath)
+			})
+			controller.Run()
+
+			sort.Strings(matches)
+			if !reflect.DeepEqual(matches, tt.matches) {
+				t.Errorf("Expected matches %v, got %v", tt.matches, matches)
+			}
+		})
+	}
+
+	// 测试用例3：代理会自行解析主机名，不能与虚拟主机模式一起使用
+	t.Run("Proxy", func(t *testing.T) {
+		controller := NewController(&parse.Options{URLs: []string{server.URL}, Wordlists: hostnames, Vhost: true, Proxies: []string{"http://127.0.0.1:8080"}})
+		if err := controller.Setup(); err == nil {
+			t.Error("Expected error when using proxies in vhost mode")
 		}
 	})
 }
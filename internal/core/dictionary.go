@@ -1,69 +1,311 @@
 package core
 
 import (
+	"regexp"
 	"strings"
+	"sync/atomic"
 
+	"HiDir/internal/common"
+	"HiDir/internal/parse"
 	"HiDir/internal/utils"
 )
 
-// Dictionary 管理字典
+var (
+	// 扩展名占位符，不区分大小写
+	extensionTagRegex = regexp.MustCompile("(?i)" + regexp.QuoteMeta(common.EXTENSION_TAG))
+	// 扩展名识别
+	extensionRegex = regexp.MustCompile(common.EXTENSION_RECOGNITION_REGEX)
+)
+
+// 字典去重使用的布隆过滤器误判率
+//
+// 误判的单词会被当作重复项跳过，n个不重复的单词中平均丢失少于 n×误判率 个，
+// 即一千万个单词最多丢失约10个。每个单词约占用3.6字节内存。
+const dictionaryFalsePositiveRate = 0.000001
+
+// Dictionary 管理字典，从磁盘逐行读取并即时生成候选路径，不在内存中保存完整字典
 type Dictionary struct {
 	files     []string
-	words     []string
-	index     int
-	processed map[string]bool
+	fileIndex int
+	reader    *utils.LineReader
+	pending   []string
+	seen      *utils.BloomFilter
+	capacity  int
+	index     int          // 已输出的单词数
+	total     atomic.Int64 // 去重后的单词总数，状态行会在扫描中读取
+
+	extensions          []string
+	excludeExtensions   []string
+	prefixes            []string
+	suffixes            []string
+	forceExtensions     bool
+	overwriteExtensions bool
+	removeExtensions    bool
+	uppercase           bool
+	lowercase           bool
+	capitalization      bool
 }
 
 // NewDictionary 创建新的Dictionary实例
 func NewDictionary(files ...string) *Dictionary {
 	return &Dictionary{
-		files:     files,
-		words:     make([]string, 0),
-		index:     0,
-		processed: make(map[string]bool),
+		files: files,
+		index: 0,
 	}
 }
 
-// Load 加载字典文件
+// SetOptions 设置字典生成选项，需在Load之前调用
+func (d *Dictionary) SetOptions(opts *parse.Options) {
+	d.extensions = splitList(opts.Extensions)
+	d.excludeExtensions = splitList(opts.ExcludeExtensions)
+	d.prefixes = splitList(opts.Prefixes)
+	d.suffixes = splitList(opts.Suffixes)
+	d.forceExtensions = opts.ForceExtensions
+	d.overwriteExtensions = opts.OverwriteExtensions
+	d.removeExtensions = opts.RemoveExtensions
+	d.uppercase = opts.Uppercase
+	d.lowercase = opts.Lowercase
+	d.capitalization = opts.Capitalization
+
+	// 扩展名统一去掉开头的点
+	for i, extension := range d.extensions {
+		d.extensions[i] = strings.TrimPrefix(extension, ".")
+	}
+	for i, extension := range d.excludeExtensions {
+		d.excludeExtensions[i] = strings.TrimPrefix(extension, ".")
+	}
+}
+
+// Load 预先扫描字典文件，估算去重所需的空间并统计去重后的单词总数
+//
+// 统计时逐行生成候选路径，只经过布隆过滤器计数而不保存，与扫描时的去重结果一致。
 func (d *Dictionary) Load() error {
+	lines := 0
 	for _, file := range d.files {
-		f := utils.NewFile(file)
-		lines := f.GetLines()
-		d.words = append(d.words, lines...)
+		lines += utils.NewFile(file).CountLines()
+	}
+
+	// 每行最多生成的候选路径数
+	multiplier := 1 + len(d.extensions)
+	if d.forceExtensions {
+		multiplier++
+	}
+	if affixes := len(d.prefixes) + len(d.suffixes); affixes > 0 {
+		multiplier *= affixes
 	}
+	d.capacity = lines * multiplier
 
-	// 去重
-	d.words = utils.Uniq(d.words)
+	// 完整生成一遍以获得去重后的数量，Reset会创建新的布隆过滤器
+	d.Reset()
+	total := 0
+	for {
+		if _, ok := d.Next(); !ok {
+			break
+		}
+		total++
+	}
+	d.total.Store(int64(total))
+	d.Reset()
 
 	return nil
 }
 
+// generate 根据一行字典内容生成最终的候选路径
+func (d *Dictionary) generate(line string) []string {
+	// 去掉开头的"/"以便添加前缀
+	line = utils.LstripOnce(strings.TrimSpace(line), "/")
+
+	if d.removeExtensions {
+		line = strings.SplitN(line, ".", 2)[0]
+	}
+
+	if !d.IsValid(line) {
+		return nil
+	}
+
+	var words []string
+
+	if strings.Contains(strings.ToLower(line), common.EXTENSION_TAG) {
+		// 替换%EXT%占位符
+		for _, extension := range d.extensions {
+			words = append(words, extensionTagRegex.ReplaceAllLiteralString(line, extension))
+		}
+	} else {
+		words = append(words, line)
+
+		if d.forceExtensions && !strings.Contains(line, ".") && !strings.HasSuffix(line, "/") {
+			// 为非目录条目强制添加扩展名
+			words = append(words, line+"/")
+			for _, extension := range d.extensions {
+				words = append(words, line+"."+extension)
+			}
+		} else if d.overwriteExtensions && d.canOverwriteExtension(line) {
+			// 覆盖已有的扩展名，同时保留原始条目
+			base := strings.SplitN(line, ".", 2)[0]
+			for _, extension := range d.extensions {
+				words = append(words, base+"."+extension)
+			}
+		}
+	}
+
+	// 添加前缀和后缀，设置后只保留添加过的条目
+	if len(d.prefixes) > 0 || len(d.suffixes) > 0 {
+		var altered []string
+		for _, word := range words {
+			for _, prefix := range d.prefixes {
+				if !strings.HasPrefix(word, "/") && !strings.HasPrefix(word, prefix) {
+					altered = append(altered, prefix+word)
+				}
+			}
+			for _, suffix := range d.suffixes {
+				// 目录和带查询、片段的路径不添加后缀
+				if !strings.HasSuffix(word, "/") && !strings.HasSuffix(word, suffix) &&
+					!strings.ContainsAny(word, "?#") {
+					altered = append(altered, word+suffix)
+				}
+			}
+		}
+		words = altered
+	}
+
+	// 大小写转换，并去除生成后带有被排除扩展名的条目
+	result := make([]string, 0, len(words))
+	for _, word := range words {
+		if d.IsValid(word) {
+			result = append(result, d.transformCase(word))
+		}
+	}
+
+	return result
+}
+
+// canOverwriteExtension 检查条目的扩展名是否可以被覆盖
+func (d *Dictionary) canOverwriteExtension(line string) bool {
+	// 带查询或片段的路径通常用于利用特定漏洞，不做修改
+	if strings.ContainsAny(line, "?#") || !extensionRegex.MatchString(line) {
+		return false
+	}
+
+	for _, extension := range append(append([]string{}, d.extensions...), common.EXCLUDE_OVERWRITE_EXTENSIONS...) {
+		if strings.HasSuffix(line, "."+extension) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// transformCase 按选项转换大小写
+func (d *Dictionary) transformCase(word string) string {
+	switch {
+	case d.lowercase:
+		return strings.ToLower(word)
+	case d.uppercase:
+		return strings.ToUpper(word)
+	case d.capitalization:
+		if word == "" {
+			return word
+		}
+		lower := strings.ToLower(word)
+		return strings.ToUpper(lower[:1]) + lower[1:]
+	default:
+		return word
+	}
+}
+
 // Next 获取下一个单词
 func (d *Dictionary) Next() (string, bool) {
-	if d.index >= len(d.words) {
-		return "", false
+	for {
+		for len(d.pending) > 0 {
+			word := d.pending[0]
+			d.pending = d.pending[1:]
+			if d.seen.TestAndAdd(word) {
+				continue
+			}
+			d.index++
+			return word, true
+		}
+
+		line, ok := d.nextLine()
+		if !ok {
+			return "", false
+		}
+		d.pending = d.generate(line)
 	}
+}
 
-	word := d.words[d.index]
-	d.index++
+// nextLine 读取下一行，当前文件结束后打开下一个文件，无法读取的文件会被跳过
+func (d *Dictionary) nextLine() (string, bool) {
+	for {
+		if d.reader == nil {
+			if d.fileIndex >= len(d.files) {
+				return "", false
+			}
+			reader, err := utils.NewFile(d.files[d.fileIndex]).NewLineReader()
+			d.fileIndex++
+			if err != nil {
+				continue
+			}
+			d.reader = reader
+		}
 
-	return word, true
+		if line, ok := d.reader.Next(); ok {
+			return line, true
+		}
+		d.reader.Close()
+		d.reader = nil
+	}
 }
 
-// Reset 重置字典索引
+// Reset 重新打开字典文件，从头开始生成
 func (d *Dictionary) Reset() {
+	if d.reader != nil {
+		d.reader.Close()
+		d.reader = nil
+	}
+	d.fileIndex = 0
+	d.pending = nil
+	d.seen = utils.NewBloomFilter(d.capacity, dictionaryFalsePositiveRate)
 	d.index = 0
 }
 
-// Len 获取字典长度
+// Index 获取已输出的单词数
+func (d *Dictionary) Index() int {
+	return d.index
+}
+
+// Len 获取去重后的字典长度，可以在生成单词的同时调用
 func (d *Dictionary) Len() int {
-	return len(d.words)
+	return int(d.total.Load())
 }
 
 // IsValid 检查路径是否有效
 func (d *Dictionary) IsValid(path string) bool {
-	// 简单实现，实际应该更复杂
-	return path != "" && !strings.HasPrefix(path, "#")
+	// 跳过空行和注释
+	if path == "" || strings.HasPrefix(path, "#") {
+		return false
+	}
+
+	// 跳过被排除的扩展名
+	cleaned := strings.SplitN(strings.SplitN(path, "?", 2)[0], "#", 2)[0]
+	for _, extension := range d.excludeExtensions {
+		if strings.HasSuffix(cleaned, "."+extension) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// splitList 拆分逗号分隔的列表，去除空项
+func splitList(value string) []string {
+	var result []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
 }
 
 // GetBlacklists 获取黑名单
@@ -0,0 +1,172 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"HiDir/internal/parse"
+)
+
+func TestDictionaryGenerate(t *testing.T) {
+	tests := []struct {
+		name     string
+		lines    []string
+		opts     parse.Options
+		expected []string
+	}{
+		{
+			name:     "SkipCommentsAndDuplicates",
+			lines:    []string{"# comment", "", "admin", "/admin", "login.php"},
+			expected: []string{"admin", "login.php"},
+		},
+		{
+			name:     "ExtensionTag",
+			lines:    []string{"index.%EXT%", "config.%ext%.bak", "robots.txt"},
+			opts:     parse.Options{Extensions: "php,asp"},
+			expected: []string{"index.php", "index.asp", "config.php.bak", "config.asp.bak", "robots.txt"},
+		},
+		{
+			name:     "ExtensionTagWithoutExtensions",
+			lines:    []string{"index.%EXT%", "admin"},
+			expected: []string{"admin"},
+		},
+		{
+			name:     "ForceExtensions",
+			lines:    []string{"admin", "login.php", "images/"},
+			opts:     parse.Options{Extensions: "php,html", ForceExtensions: true},
+			expected: []string{"admin", "admin/", "admin.php", "admin.html", "login.php", "images/"},
+		},
+		{
+			name:     "OverwriteExtensions",
+			lines:    []string{"index.jsp", "backup.zip", "page.asp?id=1", "admin"},
+			opts:     parse.Options{Extensions: "php", OverwriteExtensions: true},
+			expected: []string{"index.jsp", "index.php", "backup.zip", "page.asp?id=1", "admin"},
+		},
+		{
+			name:     "ExcludeExtensions",
+			lines:    []string{"index.php", "index.asp", "index.asp?x=1", "admin", "home.%EXT%"},
+			opts:     parse.Options{Extensions: "php,asp", ExcludeExtensions: "asp"},
+			expected: []string{"index.php", "admin", "home.php"},
+		},
+		{
+			name:     "RemoveExtensions",
+			lines:    []string{"index.php", "index.html", "login.tar.gz"},
+			opts:     parse.Options{RemoveExtensions: true},
+			expected: []string{"index", "login"},
+		},
+		{
+			name:     "Prefixes",
+			lines:    []string{"admin", ".git", "_config"},
+			opts:     parse.Options{Prefixes: ".,_"},
+			expected: []string{".admin", "_admin", "_.git", "._config"},
+		},
+		{
+			name:     "Suffixes",
+			lines:    []string{"index.php", "images/", "page?id=1", "config~"},
+			opts:     parse.Options{Suffixes: "~,.bak"},
+			expected: []string{"index.php~", "index.php.bak", "config~.bak"},
+		},
+		{
+			name:     "Uppercase",
+			lines:    []string{"Admin", "login.php"},
+			opts:     parse.Options{Uppercase: true},
+			expected: []string{"ADMIN", "LOGIN.PHP"},
+		},
+		{
+			name:     "Lowercase",
+			lines:    []string{"Admin", "ADMIN", "Login.PHP"},
+			opts:     parse.Options{Lowercase: true},
+			expected: []string{"admin", "login.php"},
+		},
+		{
+			name:     "Capitalization",
+			lines:    []string{"admin", "LOGIN.php"},
+			opts:     parse.Options{Capitalization: true},
+			expected: []string{"Admin", "Login.php"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "wordlist.txt")
+			content := ""
+			for _, line := range tt.lines {
+				content += line + "\n"
+			}
+			if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			dictionary := NewDictionary(file)
+			dictionary.SetOptions(&tt.opts)
+			if err := dictionary.Load(); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			// 加载后即为准确的长度
+			if dictionary.Len() != len(tt.expected) {
+				t.Errorf("Expected length %d, got %d", len(tt.expected), dictionary.Len())
+			}
+
+			var words []string
+			for {
+				word, ok := dictionary.Next()
+				if !ok {
+					break
+				}
+				words = append(words, word)
+			}
+
+			if !reflect.DeepEqual(words, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, words)
+			}
+
+			// 重置后重新生成相同的序列
+			dictionary.Reset()
+			if word, ok := dictionary.Next(); len(tt.expected) > 0 && (!ok || word != tt.expected[0]) {
+				t.Errorf("Expected %s after reset, got %s", tt.expected[0], word)
+			}
+		})
+	}
+}
+
+func TestDictionaryDedupe(t *testing.T) {
+	// 五十万个不重复的单词，其中每五个重复一次，去重不应误删不重复的单词
+	const unique = 500000
+	var builder strings.Builder
+	for i := 0; i < unique; i++ {
+		fmt.Fprintf(&builder, "path-%d\n", i)
+		if i%5 == 0 {
+			fmt.Fprintf(&builder, "path-%d\n", i)
+		}
+	}
+	file := filepath.Join(t.TempDir(), "large.txt")
+	if err := os.WriteFile(file, []byte(builder.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	dictionary := NewDictionary(file)
+	dictionary.SetOptions(&parse.Options{})
+	if err := dictionary.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 开始生成之前即为准确的长度
+	if dictionary.Len() != unique {
+		t.Errorf("Expected length %d before generating, got %d", unique, dictionary.Len())
+	}
+
+	count := 0
+	for {
+		if _, ok := dictionary.Next(); !ok {
+			break
+		}
+		count++
+	}
+	if count != unique {
+		t.Errorf("Expected %d unique words, got %d", unique, count)
+	}
+}
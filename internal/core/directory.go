@@ -0,0 +1,59 @@
+package core
+
+import (
+	"strings"
+
+	"HiDir/internal/connection"
+)
+
+// directory 扫描队列中的目录
+type directory struct {
+	Path   string               // 相对于目标URL的路径，以"/"结尾，根目录为空
+	Depth  int                  // 递归深度，初始目录为0
+	Parent *directory           // 发现该目录时正在扫描的目录
+	Source *connection.Response // 发现该目录的响应，初始目录为nil
+}
+
+// directoryQueue 单个目标的目录扫描队列
+type directoryQueue struct {
+	items []*directory
+	index int
+}
+
+// push 添加目录到队列末尾
+func (q *directoryQueue) push(dir *directory) {
+	q.items = append(q.items, dir)
+}
+
+// next 获取下一个待扫描的目录，扫描过程中新加入的目录也会被取出
+func (q *directoryQueue) next() (*directory, bool) {
+	if q.index >= len(q.items) {
+		return nil, false
+	}
+	dir := q.items[q.index]
+	q.index++
+	return dir, true
+}
+
+// Len 获取队列中的目录总数
+func (q *directoryQueue) Len() int {
+	return len(q.items)
+}
+
+// containsSegments 按路径段检查path中是否包含sub，"admin"匹配"x/admin/"但不匹配"x/administrator/"
+func containsSegments(path, sub string) bool {
+	sub = strings.Trim(sub, "/")
+	if sub == "" {
+		return false
+	}
+	return strings.Contains("/"+strings.Trim(path, "/")+"/", "/"+sub+"/")
+}
+
+// normalizeDirectory 规范化目录路径：去掉开头的"/"，非空时以"/"结尾
+func normalizeDirectory(path string) string {
+	path = strings.TrimLeft(strings.TrimSpace(path), "/")
+	if path != "" && !strings.HasSuffix(path, "/") {
+		path += "/"
+	}
+	return path
+}
@@ -0,0 +1,164 @@
+package core
+
+import (
+	"regexp"
+	"strings"
+
+	"HiDir/internal/connection"
+	"HiDir/internal/parse"
+	"HiDir/pkg/filter"
+)
+
+// NewIncludeStatusFilter 只保留状态码匹配的响应
+func NewIncludeStatusFilter(matcher *parse.StatusMatcher) filter.Filter {
+	return filter.NewFunc("include-status", func(response *connection.Response) filter.Verdict {
+		if !matcher.Match(response.Status) {
+			return filter.Reject("status %d not in %q", response.Status, matcher.String())
+		}
+		return filter.Accept()
+	})
+}
+
+// NewExcludeStatusFilter 丢弃状态码匹配的响应
+func NewExcludeStatusFilter(matcher *parse.StatusMatcher) filter.Filter {
+	return filter.NewFunc("exclude-status", func(response *connection.Response) filter.Verdict {
+		if matcher.Match(response.Status) {
+			return filter.Reject("status %d matches %q", response.Status, matcher.String())
+		}
+		return filter.Accept()
+	})
+}
+
+// NewSizeRangeFilter 丢弃长度不在范围内的响应，min或max为0时不限制
+func NewSizeRangeFilter(min, max int64) filter.Filter {
+	return filter.NewFunc("response-size", func(response *connection.Response) filter.Verdict {
+		if min > 0 && response.Length < min {
+			return filter.Reject("length %d is less than %d", response.Length, min)
+		}
+		if max > 0 && response.Length > max {
+			return filter.Reject("length %d is greater than %d", response.Length, max)
+		}
+		return filter.Accept()
+	})
+}
+
+// NewExcludeSizesFilter 丢弃大小匹配的响应
+func NewExcludeSizesFilter(matcher *parse.SizeMatcher) filter.Filter {
+	return filter.NewFunc("exclude-sizes", func(response *connection.Response) filter.Verdict {
+		if matcher.Match(response.Length) {
+			return filter.Reject("length %d matches %q", response.Length, matcher.String())
+		}
+		return filter.Accept()
+	})
+}
+
+// NewExcludeTextFilter 丢弃响应体包含指定文本的响应
+func NewExcludeTextFilter(texts ...string) filter.Filter {
+	return filter.NewFunc("exclude-text", func(response *connection.Response) filter.Verdict {
+		for _, text := range texts {
+			if strings.Contains(response.Content, text) {
+				return filter.Reject("body contains %q", text)
+			}
+		}
+		return filter.Accept()
+	})
+}
+
+// NewExcludeRegexFilter 丢弃响应头或响应体匹配正则的响应
+func NewExcludeRegexFilter(regex *regexp.Regexp) filter.Filter {
+	return filter.NewFunc("exclude-regex", func(response *connection.Response) filter.Verdict {
+		if regex.MatchString(response.Content) {
+			return filter.Reject("body matches %q", regex.String())
+		}
+		if regex.MatchString(formatHeaders(response.Headers)) {
+			return filter.Reject("headers match %q", regex.String())
+		}
+		return filter.Accept()
+	})
+}
+
+// NewExcludeRedirectFilter 丢弃重定向地址匹配正则的响应
+func NewExcludeRedirectFilter(regex *regexp.Regexp) filter.Filter {
+	return filter.NewFunc("exclude-redirect", func(response *connection.Response) filter.Verdict {
+		if response.Redirect != "" && regex.MatchString(response.Redirect) {
+			return filter.Reject("redirect %s matches %q", response.Redirect, regex.String())
+		}
+		return filter.Accept()
+	})
+}
+
+// NewExcludeResponseFilter 丢弃与参考页面相似的响应
+func NewExcludeResponseFilter(comparer *Comparer) filter.Filter {
+	return filter.NewFunc("exclude-response", func(response *connection.Response) filter.Verdict {
+		if score, similar := comparer.Compare(response, response.Path); similar {
+			return filter.Reject("similar to the reference page (score %.2f)", score)
+		}
+		return filter.Accept()
+	})
+}
+
+// NewWordsFilter 按单词数过滤，match为true时只保留匹配的响应，否则丢弃匹配的响应
+func NewWordsFilter(matcher *parse.RangeMatcher, match bool) filter.Filter {
+	return newCountFilter("words", matcher, match, func(response *connection.Response) int {
+		return response.Words
+	})
+}
+
+// NewLinesFilter 按行数过滤，match为true时只保留匹配的响应，否则丢弃匹配的响应
+func NewLinesFilter(matcher *parse.RangeMatcher, match bool) filter.Filter {
+	return newCountFilter("lines", matcher, match, func(response *connection.Response) int {
+		return response.Lines
+	})
+}
+
+// newCountFilter 按响应的某项计数过滤
+func newCountFilter(metric string, matcher *parse.RangeMatcher, match bool, count func(*connection.Response) int) filter.Filter {
+	name := "filter-" + metric
+	if match {
+		name = "match-" + metric
+	}
+
+	return filter.NewFunc(name, func(response *connection.Response) filter.Verdict {
+		value := count(response)
+		if matched := matcher.Match(value); matched != match {
+			if match {
+				return filter.Reject("%d %s not in %q", value, metric, matcher.String())
+			}
+			return filter.Reject("%d %s matches %q", value, metric, matcher.String())
+		}
+		return filter.Accept()
+	})
+}
+
+// NewTitleFilter 按HTML标题是否包含指定文本（不区分大小写）过滤，match为true时只保留匹配的响应，否则丢弃匹配的响应
+func NewTitleFilter(text string, match bool) filter.Filter {
+	name := "filter-title"
+	if match {
+		name = "match-title"
+	}
+	lower := strings.ToLower(text)
+
+	return filter.NewFunc(name, func(response *connection.Response) filter.Verdict {
+		if matched := strings.Contains(strings.ToLower(response.Title), lower); matched != match {
+			if match {
+				return filter.Reject("title %q does not contain %q", response.Title, text)
+			}
+			return filter.Reject("title %q contains %q", response.Title, text)
+		}
+		return filter.Accept()
+	})
+}
+
+// formatHeaders 将响应头格式化为 "Key: value" 形式的多行文本
+func formatHeaders(headers map[string][]string) string {
+	var builder strings.Builder
+	for key, values := range headers {
+		for _, value := range values {
+			builder.WriteString(key)
+			builder.WriteString(": ")
+			builder.WriteString(value)
+			builder.WriteString("\n")
+		}
+	}
+	return builder.String()
+}
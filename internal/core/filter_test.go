@@ -0,0 +1,90 @@
+package core
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"HiDir/internal/connection"
+	"HiDir/internal/parse"
+	"HiDir/pkg/filter"
+)
+
+func TestFilterChain(t *testing.T) {
+	include, err := parse.ParseStatusCodes("2xx,3xx")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 自定义过滤器：丢弃包含"debug"的响应
+	custom := filter.NewFunc("no-debug", func(response *connection.Response) filter.Verdict {
+		if strings.Contains(response.Content, "debug") {
+			return filter.Reject("debug page")
+		}
+		return filter.Accept()
+	})
+
+	chain := filter.NewChain(NewIncludeStatusFilter(include)).
+		Add(NewSizeRangeFilter(1, 0)).
+		Add(NewExcludeRedirectFilter(regexp.MustCompile("login"))).
+		Add(custom)
+
+	tests := []struct {
+		name     string
+		response *connection.Response
+		filter   string
+	}{
+		{name: "Accepted", response: &connection.Response{Status: 200, Content: "ok", Length: 2}},
+		{name: "Status", response: &connection.Response{Status: 404, Content: "ok", Length: 2}, filter: "include-status"},
+		{name: "Empty", response: &connection.Response{Status: 200}, filter: "response-size"},
+		{name: "Redirect", response: &connection.Response{Status: 302, Content: "ok", Length: 2, Redirect: "/login?next=/"}, filter: "exclude-redirect"},
+		{name: "Custom", response: &connection.Response{Status: 200, Content: "debug", Length: 5}, filter: "no-debug"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verdict := chain.Match(tt.response)
+			if verdict.Rejected != (tt.filter != "") {
+				t.Fatalf("Expected rejected=%v, got %s", tt.filter != "", verdict)
+			}
+			if verdict.Filter != tt.filter {
+				t.Errorf("Expected filter %q, got %q", tt.filter, verdict.Filter)
+			}
+			if verdict.Rejected && verdict.Reason == "" {
+				t.Error("Expected rejection reason")
+			}
+		})
+	}
+}
+
+func TestMetricFilters(t *testing.T) {
+	words, err := parse.ParseRanges("10-20")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines, err := parse.ParseRanges("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	response := &connection.Response{Status: 200, Words: 15, Lines: 1, Title: "404 Not Found"}
+
+	tests := []struct {
+		filter   filter.Filter
+		rejected bool
+	}{
+		{NewWordsFilter(words, true), false},
+		{NewWordsFilter(words, false), true},
+		{NewLinesFilter(lines, true), false},
+		{NewLinesFilter(lines, false), true},
+		{NewTitleFilter("not found", true), false},
+		{NewTitleFilter("not found", false), true},
+		{NewTitleFilter("admin", true), true},
+	}
+
+	for _, tt := range tests {
+		if verdict := tt.filter.Match(response); verdict.Rejected != tt.rejected {
+			t.Errorf("%s: expected rejected=%v, got %v", tt.filter.Name(), tt.rejected, verdict.Rejected)
+		}
+	}
+}
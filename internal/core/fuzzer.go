@@ -1,18 +1,25 @@
 package core
 
 import (
+	"fmt"
+	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"HiDir/internal/common"
 	"HiDir/internal/connection"
 	"HiDir/internal/parse"
+	"HiDir/internal/utils"
+	"HiDir/pkg/filter"
 )
 
 // MatchCallback 匹配回调函数类型
 type MatchCallback func(*connection.Response)
 
-// NotFoundCallback 未找到回调函数类型
-type NotFoundCallback func(*connection.Response)
+// NotFoundCallback 未找到回调函数类型，verdict说明响应被丢弃的原因
+type NotFoundCallback func(*connection.Response, filter.Verdict)
 
 // ErrorCallback 错误回调函数类型
 type ErrorCallback func(error)
@@ -21,7 +28,7 @@ type ErrorCallback func(error)
 type Fuzzer struct {
 	threads           []*thread
 	requester         *connection.Requester
-	dictionary        *Dictionary
+	dictionary        WordSource
 	basePath          string
 	isRunning         bool
 	paused            bool
@@ -30,7 +37,19 @@ type Fuzzer struct {
 	matchCallbacks    []MatchCallback
 	notFoundCallbacks []NotFoundCallback
 	errorCallbacks    []ErrorCallback
-	opts              *parse.Options // 添加选项字段
+	opts              *parse.Options                 // 添加选项字段
+	scanners          map[string]map[string]*Scanner // 按基础路径和上下文缓存的通配符测试
+	filters           *filter.Chain                  // 响应过滤器
+	template          bool                           // 请求替换占位符而不是追加路径
+	jobs              chan *job                      // 待请求的单词
+	results           chan *result                   // 已检查的请求结果，由单独的协程依次分发给回调
+	done              chan struct{}                  // 本轮扫描结束时关闭
+	issued            atomic.Int64
+	completed         atomic.Int64
+	failed            atomic.Int64
+	position          atomic.Int64 // 字典中该位置之前的单词都已处理完毕
+	processed         map[int]bool // 已处理但位于position之后的单词，只由dispatch访问
+	skip              map[int]bool // 恢复会话时已处理过的单词，只由produce访问
 }
 
 // thread 表示一个扫描线程
@@ -40,8 +59,56 @@ type thread struct {
 	wg     *sync.WaitGroup
 }
 
+// job 一个待请求的单词
+type job struct {
+	index  int // 单词在字典中的位置
+	word   string
+	values map[string]string // 多个字典组合时各占位符的值
+}
+
+// payload 获取请求中替换各占位符的值，按占位符排序
+func (j *job) payload() []string {
+	if j.values == nil {
+		return []string{j.word}
+	}
+
+	keywords := make([]string, 0, len(j.values))
+	for keyword := range j.values {
+		keywords = append(keywords, keyword)
+	}
+	sort.Strings(keywords)
+	values := make([]string, len(keywords))
+	for i, keyword := range keywords {
+		values[i] = j.values[keyword]
+	}
+	return values
+}
+
+// result 一次请求的结果
+type result struct {
+	index    int
+	word     string
+	values   []string // 请求中替换各占位符的值，按路径扫描时为单词本身
+	response *connection.Response
+	verdict  filter.Verdict // 过滤器和通配符检测的结果，由发出请求的线程计算
+	err      error
+}
+
+// FuzzerProgress 字典的处理进度，用于保存和恢复会话
+type FuzzerProgress struct {
+	Position  int   `json:"position"`            // 该位置之前的单词都已处理
+	Processed []int `json:"processed,omitempty"` // 位于Position之后但已处理的单词
+}
+
+// FuzzerStats 扫描任务统计
+type FuzzerStats struct {
+	Issued    int64 // 已发出的请求数
+	Completed int64 // 已完成的请求数
+	Failed    int64 // 失败的请求数
+}
+
 // NewFuzzer 创建新的Fuzzer实例
-func NewFuzzer(requester *connection.Requester, dictionary *Dictionary) *Fuzzer {
+func NewFuzzer(requester *connection.Requester, dictionary WordSource) *Fuzzer {
 	fuzzer := &Fuzzer{
 		requester:         requester,
 		dictionary:        dictionary,
@@ -50,6 +117,7 @@ func NewFuzzer(requester *connection.Requester, dictionary *Dictionary) *Fuzzer
 		matchCallbacks:    make([]MatchCallback, 0),
 		notFoundCallbacks: make([]NotFoundCallback, 0),
 		errorCallbacks:    make([]ErrorCallback, 0),
+		scanners:          make(map[string]map[string]*Scanner),
 	}
 	fuzzer.cond = sync.NewCond(&fuzzer.mutex)
 
@@ -61,6 +129,11 @@ func (f *Fuzzer) SetOptions(opts *parse.Options) {
 	f.opts = opts
 }
 
+// SetTemplate 设置请求是否替换占位符，替换时通配符检测按占位符的值比较
+func (f *Fuzzer) SetTemplate(template bool) {
+	f.template = template
+}
+
 // AddMatchCallback 添加匹配回调
 func (f *Fuzzer) AddMatchCallback(callback MatchCallback) {
 	f.matchCallbacks = append(f.matchCallbacks, callback)
@@ -76,6 +149,16 @@ func (f *Fuzzer) AddErrorCallback(callback ErrorCallback) {
 	f.errorCallbacks = append(f.errorCallbacks, callback)
 }
 
+// ResetScanners 清除已缓存的通配符测试，切换目标时调用
+func (f *Fuzzer) ResetScanners() {
+	f.scanners = make(map[string]map[string]*Scanner)
+}
+
+// SetFilters 设置响应过滤器，通配符检测在过滤器之后执行
+func (f *Fuzzer) SetFilters(filters *filter.Chain) {
+	f.filters = filters
+}
+
 // SetBasePath 设置基础路径
 func (f *Fuzzer) SetBasePath(path string) {
 	f.basePath = path
@@ -90,14 +173,37 @@ func (f *Fuzzer) Start(threadCount int) {
 		return
 	}
 
+	// 暂停状态保留到Resume或Stop，目录之间的暂停对下一个目录同样有效
 	f.isRunning = true
-	f.paused = false
+
+	// 通配符测试失败时错误回调可能保存会话，在此之前重置本轮的进度
+	f.issued.Store(0)
+	f.completed.Store(0)
+	f.failed.Store(0)
+	f.position.Store(int64(f.dictionary.Index()))
+	f.processed = make(map[int]bool, len(f.skip))
+	for index := range f.skip {
+		f.processed[index] = true
+	}
+	f.mutex.Unlock()
+
+	// 为当前目录进行通配符测试，测试失败时错误回调可能调用Stop，不能持有锁
+	f.setupScanners()
+
+	f.mutex.Lock()
 
 	// 设置默认线程数
 	if threadCount <= 0 {
 		threadCount = 10 // 默认10个线程
 	}
 
+	f.jobs = make(chan *job, threadCount*2)
+	f.results = make(chan *result, threadCount*2)
+	f.done = make(chan struct{})
+
+	// 生产者：从字典读取单词放入任务队列
+	go f.produce()
+
 	// 创建线程
 	var wg sync.WaitGroup
 	f.threads = make([]*thread, threadCount)
@@ -113,13 +219,157 @@ func (f *Fuzzer) Start(threadCount int) {
 		go t.run()
 	}
 
-	// 等待所有线程完成
+	// 所有线程结束后关闭结果队列
 	go func() {
 		wg.Wait()
+		close(f.results)
+	}()
+
+	// 依次处理结果，回调不会被并发调用
+	go f.dispatch()
+}
+
+// produce 从字典读取单词并放入任务队列，字典只由该协程访问
+func (f *Fuzzer) produce() {
+	defer close(f.jobs)
+
+	for {
+		if !f.waitIfPaused() {
+			return
+		}
+
+		word, ok := f.dictionary.Next()
+		if !ok {
+			return
+		}
+
+		index := f.dictionary.Index() - 1
+		if f.skip[index] {
+			continue
+		}
+		item := &job{index: index, word: word}
+		if combinator, ok := f.dictionary.(*Combinator); ok {
+			item.values = combinator.Values()
+		}
+		f.jobs <- item
+	}
+}
+
+// dispatch 依次调用回调，响应在线程中已检查完毕
+func (f *Fuzzer) dispatch() {
+	defer func() {
 		f.mutex.Lock()
 		f.isRunning = false
 		f.mutex.Unlock()
+		f.skip = nil
+		close(f.done)
 	}()
+
+	for r := range f.results {
+		// 先记录处理进度，回调中保存的会话包含当前单词的处理结果
+		f.advance(r.index)
+
+		if r.err != nil {
+			f.failed.Add(1)
+			// 调用错误回调
+			for _, callback := range f.errorCallbacks {
+				callback(r.err)
+			}
+			continue
+		}
+
+		f.completed.Add(1)
+
+		if !r.verdict.Rejected {
+			// 调用匹配回调
+			for _, callback := range f.matchCallbacks {
+				callback(r.response)
+			}
+		} else {
+			// 调用未找到回调
+			for _, callback := range f.notFoundCallbacks {
+				callback(r.response, r.verdict)
+			}
+		}
+	}
+}
+
+// advance 标记单词已处理，并推进连续处理完毕的位置
+func (f *Fuzzer) advance(index int) {
+	f.processed[index] = true
+	position := int(f.position.Load())
+	for f.processed[position] {
+		delete(f.processed, position)
+		position++
+	}
+	f.position.Store(int64(position))
+}
+
+// Progress 获取本轮扫描的处理进度，只能在回调中或者扫描结束后调用
+func (f *Fuzzer) Progress() FuzzerProgress {
+	progress := FuzzerProgress{Position: int(f.position.Load())}
+	for index := range f.processed {
+		progress.Processed = append(progress.Processed, index)
+	}
+	sort.Ints(progress.Processed)
+	return progress
+}
+
+// Restore 从保存的进度继续，需要在Start之前、字典从头开始时调用
+func (f *Fuzzer) Restore(progress FuzzerProgress) {
+	for f.dictionary.Index() < progress.Position {
+		if _, ok := f.dictionary.Next(); !ok {
+			break
+		}
+	}
+
+	f.skip = make(map[int]bool, len(progress.Processed))
+	for _, index := range progress.Processed {
+		f.skip[index] = true
+	}
+}
+
+// waitIfPaused 暂停时阻塞，返回是否应继续运行
+func (f *Fuzzer) waitIfPaused() bool {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	for f.paused && f.isRunning {
+		f.cond.Wait()
+	}
+
+	return f.isRunning
+}
+
+// acquire 暂停时阻塞，继续运行时计入已发出的请求，返回是否应发出请求
+//
+// 计数与暂停检查在同一把锁中进行，Pause返回后不会再有新的请求发出。
+func (f *Fuzzer) acquire() bool {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	for f.paused && f.isRunning {
+		f.cond.Wait()
+	}
+	if f.isRunning {
+		f.issued.Add(1)
+	}
+	return f.isRunning
+}
+
+// Drain 等待已发出的请求完成并处理完毕，超时返回false，用于暂停后等待
+func (f *Fuzzer) Drain(timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for {
+		stats := f.Stats()
+		if stats.Completed+stats.Failed >= stats.Issued || !f.IsRunning() {
+			return true
+		}
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
 }
 
 // Stop 停止扫描
@@ -132,7 +382,7 @@ func (f *Fuzzer) Stop() {
 	f.cond.Broadcast()
 }
 
-// Pause 暂停扫描
+// Pause 暂停扫描，在两次扫描之间调用时下一次Start后不会发出请求，直到Resume
 func (f *Fuzzer) Pause() {
 	f.mutex.Lock()
 	defer f.mutex.Unlock()
@@ -157,30 +407,38 @@ func (f *Fuzzer) IsRunning() bool {
 	return f.isRunning
 }
 
+// Stats 获取本轮扫描的任务统计
+func (f *Fuzzer) Stats() FuzzerStats {
+	return FuzzerStats{
+		Issued:    f.issued.Load(),
+		Completed: f.completed.Load(),
+		Failed:    f.failed.Load(),
+	}
+}
+
 // Wait 等待扫描完成
 func (f *Fuzzer) Wait(timeout ...time.Duration) bool {
+	f.mutex.Lock()
+	done := f.done
+	f.mutex.Unlock()
+
+	if done == nil {
+		return true
+	}
+
 	if len(timeout) > 0 {
 		timer := time.NewTimer(timeout[0])
 		defer timer.Stop()
 
-		for {
-			if !f.IsRunning() {
-				return true
-			}
-
-			select {
-			case <-timer.C:
-				return false
-			default:
-				time.Sleep(100 * time.Millisecond)
-			}
+		select {
+		case <-done:
+			return true
+		case <-timer.C:
+			return false
 		}
 	}
 
-	for f.IsRunning() {
-		time.Sleep(100 * time.Millisecond)
-	}
-
+	<-done
 	return true
 }
 
@@ -188,100 +446,157 @@ func (f *Fuzzer) Wait(timeout ...time.Duration) bool {
 func (t *thread) run() {
 	defer t.wg.Done()
 
-	for {
-		// 检查是否需要暂停
-		t.fuzzer.mutex.Lock()
-		for t.fuzzer.paused {
-			t.fuzzer.cond.Wait()
+	for job := range t.fuzzer.jobs {
+		// 暂停时等待，停止后丢弃剩余任务
+		if !t.fuzzer.acquire() {
+			continue
 		}
 
-		// 检查是否需要停止
-		if !t.fuzzer.isRunning {
-			t.fuzzer.mutex.Unlock()
-			return
+		// 构建完整路径并发送请求，多个字典组合时替换各占位符
+		var (
+			response *connection.Response
+			err      error
+		)
+		if job.values != nil {
+			response, err = t.fuzzer.requester.RequestValues(job.values)
+		} else {
+			response, err = t.fuzzer.requester.Request(t.fuzzer.basePath + job.word)
 		}
-		t.fuzzer.mutex.Unlock()
+		r := &result{index: job.index, word: job.word, values: job.payload(), response: response, err: err}
 
-		// 获取下一个单词
-		word, ok := t.fuzzer.dictionary.Next()
-		if !ok {
-			break
+		// 过滤器和通配符比较开销较大，在各线程中并行执行，只有回调依次调用
+		if err == nil {
+			r.verdict = t.fuzzer.checkResponse(r.values, response)
 		}
+		t.fuzzer.results <- r
 
-		// 构建完整路径
-		path := t.fuzzer.basePath + word
+		// 延迟
+		if t.fuzzer.opts != nil && t.fuzzer.opts.Delay > 0 {
+			time.Sleep(time.Duration(t.fuzzer.opts.Delay * float64(time.Second)))
+		}
+	}
+}
 
-		// 发送请求
-		response, err := t.fuzzer.requester.Request(path)
-		if err != nil {
-			// 调用错误回调
-			for _, callback := range t.fuzzer.errorCallbacks {
+// setupScanners 为当前基础路径建立通配符测试，已测试过的路径直接复用
+func (f *Fuzzer) setupScanners() {
+	if _, ok := f.scanners[f.basePath]; ok {
+		return
+	}
+	scanners := make(map[string]*Scanner)
+	f.scanners[f.basePath] = scanners
+
+	add := func(context, path string) {
+		scanner := NewScanner(f.requester, path, f.scanners, context)
+		scanner.SetTemplate(f.template)
+		if f.opts != nil {
+			scanner.SetThreshold(f.opts.SimilarityThreshold)
+		}
+		if err := scanner.Setup(); err != nil {
+			for _, callback := range f.errorCallbacks {
 				callback(err)
 			}
-			continue
+			return
 		}
+		scanners[context] = scanner
+	}
 
-		// 检查响应是否有效
-		if t.fuzzer.isValidResponse(response) {
-			// 调用匹配回调
-			for _, callback := range t.fuzzer.matchCallbacks {
-				callback(response)
-			}
-		} else {
-			// 调用未找到回调
-			for _, callback := range t.fuzzer.notFoundCallbacks {
-				callback(response)
-			}
-		}
+	// 虚拟主机模式只与随机主机名的响应比较，即服务器的默认虚拟主机
+	if f.opts != nil && f.opts.Vhost {
+		add("default vhost", common.WILDCARD_TEST_POINT_MARKER)
+		return
+	}
 
-		// 延迟
-		if t.fuzzer.opts != nil && t.fuzzer.opts.Delay > 0 {
-			time.Sleep(time.Duration(t.fuzzer.opts.Delay) * time.Second)
-		}
+	// 目录首页与随机路径
+	add("index", f.basePath)
+	add("random", f.basePath+common.WILDCARD_TEST_POINT_MARKER)
+
+	// 前缀
+	for _, prefix := range f.testPrefixes() {
+		add("prefix:"+prefix, f.basePath+prefix+common.WILDCARD_TEST_POINT_MARKER)
+	}
+
+	// 后缀与扩展名
+	for _, suffix := range f.testSuffixes() {
+		add("suffix:"+suffix, f.basePath+common.WILDCARD_TEST_POINT_MARKER+suffix)
 	}
 }
 
-// isValidResponse 检查响应是否有效
-func (f *Fuzzer) isValidResponse(response *connection.Response) bool {
-	// 检查状态码
+// testPrefixes 获取需要测试的前缀
+func (f *Fuzzer) testPrefixes() []string {
+	prefixes := append([]string{}, common.DEFAULT_TEST_PREFIXES...)
 	if f.opts != nil {
-		// 检查排除状态码
-		if f.opts.ExcludeStatusCodes != "" {
-			// 简化实现，实际应该解析状态码列表
-		}
+		prefixes = append(prefixes, splitList(f.opts.Prefixes)...)
+	}
+	return utils.Uniq(prefixes)
+}
 
-		// 检查包含状态码
-		if f.opts.IncludeStatusCodes != "" {
-			// 简化实现，实际应该解析状态码列表
+// testSuffixes 获取需要测试的后缀，扩展名以后缀形式测试
+func (f *Fuzzer) testSuffixes() []string {
+	suffixes := append([]string{}, common.DEFAULT_TEST_SUFFIXES...)
+	if f.opts != nil {
+		suffixes = append(suffixes, splitList(f.opts.Suffixes)...)
+		for _, extension := range splitList(f.opts.Extensions) {
+			suffixes = append(suffixes, "."+strings.TrimPrefix(extension, "."))
 		}
+	}
+	return utils.Uniq(suffixes)
+}
 
-		// 检查内容长度
-		if f.opts.MinimumResponseSize > 0 {
-			if response.Length < int64(f.opts.MinimumResponseSize) {
-				return false
+// getScannersFor 获取适用于这些值的通配符测试，任意一个值带有前缀或后缀即适用
+func (f *Fuzzer) getScannersFor(values []string) []*Scanner {
+	scanners := f.scanners[f.basePath]
+	match := func(affix func(s, affix string) bool, context, prefix string) bool {
+		for _, value := range values {
+			if affix(strings.TrimSuffix(value, "/"), strings.TrimPrefix(context, prefix)) {
+				return true
 			}
 		}
+		return false
+	}
 
-		if f.opts.MaximumResponseSize > 0 {
-			if response.Length > int64(f.opts.MaximumResponseSize) {
-				return false
+	var result []*Scanner
+	for context, scanner := range scanners {
+		switch {
+		case strings.HasPrefix(context, "prefix:"):
+			if match(strings.HasPrefix, context, "prefix:") {
+				result = append(result, scanner)
 			}
-		}
-
-		// 检查内容
-		if len(f.opts.ExcludeTexts) > 0 {
-			for _, text := range f.opts.ExcludeTexts {
-				if contains(response.Content, text) {
-					return false
-				}
+		case strings.HasPrefix(context, "suffix:"):
+			if match(strings.HasSuffix, context, "suffix:") {
+				result = append(result, scanner)
 			}
+		default:
+			result = append(result, scanner)
 		}
 	}
 
-	return true
+	return result
 }
 
-// contains 检查字符串是否包含指定文本
-func contains(s, substr string) bool {
-	return len(s) >= len(substr) && (substr == "" || s[0:len(s)] == substr || contains(s[1:], substr))
+// checkResponse 依次执行过滤器和通配符检测，判定响应是否有效，values为请求中替换各占位符的值
+//
+// 由多个线程并发调用，过滤器和通配符测试在扫描开始后只读。
+func (f *Fuzzer) checkResponse(values []string, response *connection.Response) filter.Verdict {
+	if f.filters != nil {
+		if verdict := f.filters.Match(response); verdict.Rejected {
+			return verdict
+		}
+	}
+
+	// 与通配符响应相似的视为无效
+	var reflected []string
+	if f.template {
+		reflected = values
+	}
+	for _, scanner := range f.getScannersFor(values) {
+		if !scanner.Check(response.Path, response, reflected...) {
+			return filter.Verdict{
+				Rejected: true,
+				Filter:   "wildcard",
+				Reason:   fmt.Sprintf("matches the %s wildcard response", scanner.Context()),
+			}
+		}
+	}
+
+	return filter.Accept()
 }
@@ -0,0 +1,239 @@
+package core
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"HiDir/internal/connection"
+	"HiDir/pkg/filter"
+)
+
+func TestFuzzerJobQueue(t *testing.T) {
+	const total = 2000
+
+	// 记录每个路径被请求的次数
+	var mutex sync.Mutex
+	requested := make(map[string]int)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		requested[r.URL.Path]++
+		mutex.Unlock()
+
+		if strings.HasPrefix(r.URL.Path, "/found-") {
+			fmt.Fprint(w, "found")
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	var lines []string
+	for i := 0; i < total; i++ {
+		if i%100 == 0 {
+			lines = append(lines, fmt.Sprintf("found-%d", i))
+		} else {
+			lines = append(lines, fmt.Sprintf("word-%d", i))
+		}
+	}
+	file := filepath.Join(t.TempDir(), "wordlist.txt")
+	if err := os.WriteFile(file, []byte(strings.Join(lines, "\n")), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	dictionary := NewDictionary(file)
+	if err := dictionary.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	requester := connection.NewRequester()
+	requester.SetURL(server.URL)
+
+	fuzzer := NewFuzzer(requester, dictionary)
+	var matches []string
+	fuzzer.AddMatchCallback(func(response *connection.Response) {
+		matches = append(matches, response.Path)
+	})
+
+	fuzzer.Start(200)
+	fuzzer.Wait()
+
+	stats := fuzzer.Stats()
+	if stats.Issued != total || stats.Completed != total || stats.Failed != 0 {
+		t.Errorf("Expected %d issued and completed jobs, got %+v", total, stats)
+	}
+	if len(matches) != total/100 {
+		t.Errorf("Expected %d matches, got %d", total/100, len(matches))
+	}
+	for _, line := range lines {
+		if requested["/"+line] != 1 {
+			t.Errorf("Expected %s to be requested once, got %d", line, requested["/"+line])
+		}
+	}
+}
+
+func TestFuzzerParallelFilters(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	file := filepath.Join(t.TempDir(), "wordlist.txt")
+	if err := os.WriteFile(file, []byte("a\nb\nc\nd\ne\nf\ng\nh"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	dictionary := NewDictionary(file)
+	if err := dictionary.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	requester := connection.NewRequester()
+	requester.SetURL(server.URL)
+
+	// 记录同时执行过滤器的线程数
+	var mutex sync.Mutex
+	active, maxActive, calls := 0, 0, 0
+	fuzzer := NewFuzzer(requester, dictionary)
+	fuzzer.SetFilters(filter.NewChain(filter.NewFunc("slow", func(response *connection.Response) filter.Verdict {
+		mutex.Lock()
+		active++
+		calls++
+		maxActive = max(maxActive, active)
+		mutex.Unlock()
+
+		time.Sleep(50 * time.Millisecond)
+
+		mutex.Lock()
+		active--
+		mutex.Unlock()
+		return filter.Accept()
+	})))
+	fuzzer.Start(4)
+	fuzzer.Wait()
+
+	if maxActive < 2 {
+		t.Errorf("Expected filters to run in parallel, got at most %d at a time", maxActive)
+	}
+	if calls != 8 {
+		t.Errorf("Expected filters to run for 8 responses, got %d", calls)
+	}
+}
+
+func TestFuzzerPauseBetweenDirectories(t *testing.T) {
+	var mutex sync.Mutex
+	requested := make(map[string]bool)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		requested[r.URL.Path] = true
+		mutex.Unlock()
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	file := filepath.Join(t.TempDir(), "wordlist.txt")
+	if err := os.WriteFile(file, []byte("a\nb\nc"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	dictionary := NewDictionary(file)
+	if err := dictionary.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	requester := connection.NewRequester()
+	requester.SetURL(server.URL)
+	fuzzer := NewFuzzer(requester, dictionary)
+
+	fuzzer.Start(2)
+	fuzzer.Wait()
+
+	// 第一个目录结束后、第二个目录开始前暂停
+	fuzzer.Pause()
+	dictionary.Reset()
+	fuzzer.SetBasePath("admin/")
+	fuzzer.Start(2)
+
+	time.Sleep(100 * time.Millisecond)
+	if stats := fuzzer.Stats(); stats.Issued != 0 {
+		t.Errorf("Expected no requests while paused, got %+v", stats)
+	}
+
+	fuzzer.Resume()
+	if !fuzzer.Wait(5 * time.Second) {
+		t.Fatal("Expected scan to finish after resuming")
+	}
+	mutex.Lock()
+	defer mutex.Unlock()
+	for _, path := range []string{"/admin/a", "/admin/b", "/admin/c"} {
+		if !requested[path] {
+			t.Errorf("Expected %s to be requested after resuming", path)
+		}
+	}
+}
+
+func TestFuzzerStopDuringCalibration(t *testing.T) {
+	// 目标不可达，通配符测试失败时错误回调中停止扫描不应死锁
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close()
+
+	file := filepath.Join(t.TempDir(), "wordlist.txt")
+	if err := os.WriteFile(file, []byte("admin\nlogin"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	dictionary := NewDictionary(file)
+	if err := dictionary.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	requester := connection.NewRequester()
+	requester.SetURL(url)
+
+	fuzzer := NewFuzzer(requester, dictionary)
+	fuzzer.AddErrorCallback(func(err error) {
+		fuzzer.Stop()
+	})
+
+	done := make(chan struct{})
+	go func() {
+		fuzzer.Start(2)
+		fuzzer.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("Fuzzer deadlocked when stopped during calibration")
+	}
+}
+
+func TestFuzzerScannersForValues(t *testing.T) {
+	random, prefix, suffix := &Scanner{}, &Scanner{}, &Scanner{}
+	fuzzer := NewFuzzer(connection.NewRequester(), NewDictionary())
+	fuzzer.scanners[""] = map[string]*Scanner{"random": random, "prefix:_": prefix, "suffix:.bak": suffix}
+
+	tests := []struct {
+		name     string
+		values   []string
+		expected int
+	}{
+		// 测试用例1：只适用随机路径的测试
+		{"Plain", []string{"admin"}, 1},
+		// 测试用例2：任意一个值带有前缀即适用，不受其他占位符的值影响
+		{"Prefix", []string{"admin", "_secret"}, 2},
+		// 测试用例3：前缀和后缀
+		{"PrefixAndSuffix", []string{"_config", "db.bak"}, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := len(fuzzer.getScannersFor(tt.values)); got != tt.expected {
+				t.Errorf("Expected %d scanners, got %d", tt.expected, got)
+			}
+		})
+	}
+}
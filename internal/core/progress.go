@@ -0,0 +1,123 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 进度条宽度
+const progressBarWidth = 20
+
+// scanProgress 当前目标的扫描进度，用于显示状态行
+type scanProgress struct {
+	mutex       sync.Mutex
+	target      string
+	directory   string
+	index       int   // 正在扫描第几个目录，从1开始
+	directories int   // 已知的目录总数，递归时会增加
+	finished    int64 // 已扫描完的目录中处理的请求数
+	failed      int64 // 已扫描完的目录中失败的请求数
+	restored    int64 // 恢复会话时跳过的已处理单词数，计入进度但不计入速度
+	running     bool  // 当前目录的统计是否有效
+	start       time.Time
+}
+
+// reset 开始扫描新目标
+func (p *scanProgress) reset(target string) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.target = target
+	p.directory = ""
+	p.index = 0
+	p.directories = 0
+	p.finished = 0
+	p.failed = 0
+	p.restored = 0
+	p.running = false
+	p.start = time.Now()
+}
+
+// setDirectories 更新已知的目录总数
+func (p *scanProgress) setDirectories(count int) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.directories = count
+}
+
+// startDirectory 开始扫描目录，需要在Fuzzer.Start之后调用，restored为从会话恢复时已处理的单词数
+func (p *scanProgress) startDirectory(path string, index int, restored int64) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.directory = path
+	p.index = index
+	p.finished += restored
+	p.restored += restored
+	p.running = true
+}
+
+// finishDirectory 目录扫描结束，累计该目录的统计
+func (p *scanProgress) finishDirectory(stats FuzzerStats) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.finished += stats.Completed + stats.Failed
+	p.failed += stats.Failed
+	p.running = false
+}
+
+// format 生成状态行
+func (p *scanProgress) format(stats FuzzerStats, words int) string {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.target == "" {
+		return ""
+	}
+
+	done, failed := p.finished, p.failed
+	if p.running {
+		done += stats.Completed + stats.Failed
+		failed += stats.Failed
+	}
+	// 字典长度在加载时已准确统计，总数与剩余时间据此计算
+	total := int64(words) * int64(p.directories)
+	if total < done {
+		total = done
+	}
+
+	percent := 100.0
+	if total > 0 {
+		percent = float64(done) * 100 / float64(total)
+	}
+	filled := int(percent) * progressBarWidth / 100
+	bar := strings.Repeat("#", filled) + strings.Repeat("-", progressBarWidth-filled)
+
+	elapsed := time.Since(p.start).Seconds()
+	rate := 0.0
+	if elapsed > 0 {
+		rate = float64(done-p.restored) / elapsed
+	}
+	eta := "--:--:--"
+	if rate > 0 {
+		eta = formatDuration(time.Duration(float64(total-done) / rate * float64(time.Second)))
+	}
+
+	target := p.target
+	if !strings.HasSuffix(target, "/") {
+		target += "/"
+	}
+
+	return fmt.Sprintf("[%s] %3.0f%% %d/%d  %.0f req/s  errors: %d  ETA %s  job %d/%d: %s%s",
+		bar, percent, done, total, rate, failed, eta, p.index, p.directories, target, p.directory)
+}
+
+// formatDuration 将时长格式化为 时:分:秒
+func formatDuration(d time.Duration) string {
+	seconds := int64(d.Round(time.Second) / time.Second)
+	return fmt.Sprintf("%02d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+}
@@ -0,0 +1,68 @@
+package core
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestScanProgress(t *testing.T) {
+	// 测试用例1：统计已完成目录和当前目录的请求
+	t.Run("Format", func(t *testing.T) {
+		var progress scanProgress
+		progress.reset("http://example.com")
+		progress.setDirectories(2)
+
+		progress.startDirectory("", 1, 0)
+		progress.finishDirectory(FuzzerStats{Completed: 90, Failed: 10})
+		progress.startDirectory("admin/", 2, 0)
+
+		status := progress.format(FuzzerStats{Completed: 45, Failed: 5}, 100)
+		for _, expected := range []string{"[###############-----]", " 75%", "150/200", "errors: 15", "job 2/2: http://example.com/admin/"} {
+			if !strings.Contains(status, expected) {
+				t.Errorf("Expected %q in status %q", expected, status)
+			}
+		}
+	})
+
+	// 测试用例2：递归发现的目录使总数增加
+	t.Run("DirectoriesAdded", func(t *testing.T) {
+		var progress scanProgress
+		progress.reset("http://example.com/")
+		progress.setDirectories(1)
+		progress.startDirectory("", 1, 0)
+		progress.setDirectories(3)
+
+		status := progress.format(FuzzerStats{Completed: 50}, 100)
+		if !strings.Contains(status, "50/300") || !strings.Contains(status, "job 1/3: http://example.com/") {
+			t.Errorf("Unexpected status %q", status)
+		}
+	})
+
+	// 测试用例3：恢复会话时已处理的单词计入进度
+	t.Run("Restored", func(t *testing.T) {
+		var progress scanProgress
+		progress.reset("http://example.com/")
+		progress.setDirectories(1)
+		progress.startDirectory("", 1, 40)
+
+		status := progress.format(FuzzerStats{Completed: 60}, 100)
+		if !strings.Contains(status, "100%") || !strings.Contains(status, "100/100") {
+			t.Errorf("Unexpected status %q", status)
+		}
+	})
+
+	// 测试用例4：未开始扫描时没有状态行
+	t.Run("NotStarted", func(t *testing.T) {
+		var progress scanProgress
+		if status := progress.format(FuzzerStats{}, 100); status != "" {
+			t.Errorf("Expected empty status, got %q", status)
+		}
+	})
+}
+
+func TestFormatDuration(t *testing.T) {
+	if got := formatDuration(3*time.Hour + 25*time.Minute + 7*time.Second); got != "03:25:07" {
+		t.Errorf("Expected 03:25:07, got %s", got)
+	}
+}
@@ -0,0 +1,90 @@
+package core
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"HiDir/internal/connection"
+)
+
+// 等待重放的请求数上限
+const replayQueueSize = 1024
+
+// 重放请求的并发数
+const replayWorkers = 2
+
+// 重放队列已满时等待空位的最长时间
+const replayQueueTimeout = 5 * time.Second
+
+// errReplayQueueFull 重放队列已满
+var errReplayQueueFull = errors.New("replay queue is full")
+
+// replayer 通过重放代理异步重新发送匹配的请求，不影响扫描速度
+type replayer struct {
+	requester *connection.Requester
+	proxy     string
+	queue     chan *connection.SentRequest
+	timeout   time.Duration
+	dropped   atomic.Int64
+	wg        sync.WaitGroup
+	onError   func(url string, err error)
+}
+
+// newReplayer 创建新的replayer实例并启动发送请求的goroutine
+func newReplayer(requester *connection.Requester, proxy string, onError func(url string, err error)) (*replayer, error) {
+	if _, err := connection.ParseProxy(proxy); err != nil {
+		return nil, err
+	}
+
+	r := &replayer{
+		requester: requester,
+		proxy:     proxy,
+		queue:     make(chan *connection.SentRequest, replayQueueSize),
+		timeout:   replayQueueTimeout,
+		onError:   onError,
+	}
+	for i := 0; i < replayWorkers; i++ {
+		r.wg.Add(1)
+		go r.run()
+	}
+
+	return r, nil
+}
+
+// add 将实际发送的请求加入重放队列，队列已满时等待空位，超时后放弃重放并计数
+func (r *replayer) add(request *connection.SentRequest) {
+	select {
+	case r.queue <- request:
+		return
+	default:
+	}
+
+	timer := time.NewTimer(r.timeout)
+	defer timer.Stop()
+	select {
+	case r.queue <- request:
+	case <-timer.C:
+		r.dropped.Add(1)
+		r.onError(request.URL, errReplayQueueFull)
+	}
+}
+
+// close 等待队列中的请求发送完毕，返回因队列已满而放弃重放的请求数
+func (r *replayer) close() int64 {
+	close(r.queue)
+	r.wg.Wait()
+	return r.dropped.Load()
+}
+
+// run 发送队列中的请求
+func (r *replayer) run() {
+	defer r.wg.Done()
+
+	for request := range r.queue {
+		if err := r.requester.Replay(request, r.proxy); err != nil {
+			r.onError(request.URL, err)
+		}
+	}
+}
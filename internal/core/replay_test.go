@@ -0,0 +1,143 @@
+package core
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"HiDir/internal/connection"
+	"HiDir/internal/parse"
+)
+
+func TestControllerReplayProxy(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/admin" {
+			w.Write([]byte("admin"))
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer target.Close()
+
+	var (
+		mutex    sync.Mutex
+		replayed []string
+	)
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mutex.Lock()
+		replayed = append(replayed, r.Method+" "+r.URL.String()+" "+r.Header.Get("X-Test")+" "+string(body))
+		mutex.Unlock()
+	}))
+	defer proxy.Close()
+
+	wordlist := filepath.Join(t.TempDir(), "wordlist.txt")
+	if err := os.WriteFile(wordlist, []byte("admin\nlogin\nbackup\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// 测试用例1：匹配的请求通过重放代理重新发送，使用相同的请求方法、请求头和请求数据
+	t.Run("ReplayMatches", func(t *testing.T) {
+		controller := NewController(&parse.Options{
+			URLs:        []string{target.URL + "/"},
+			Wordlists:   wordlist,
+			HTTPMethod:  "POST",
+			Data:        "id=1",
+			Headers:     []string{"X-Test: replay"},
+			ReplayProxy: proxy.URL,
+		})
+		if err := controller.Setup(); err != nil {
+			t.Fatal(err)
+		}
+		controller.Run()
+
+		mutex.Lock()
+		defer mutex.Unlock()
+		expected := "POST " + target.URL + "/admin replay id=1"
+		if len(replayed) != 1 || replayed[0] != expected {
+			t.Errorf("Expected replayed request %q, got %v", expected, replayed)
+		}
+	})
+
+	// 测试用例2：重放替换占位符后的请求，而不是带有占位符的模板
+	t.Run("ReplayTemplate", func(t *testing.T) {
+		mutex.Lock()
+		replayed = nil
+		mutex.Unlock()
+
+		controller := NewController(&parse.Options{
+			URLs:        []string{target.URL + "/FUZZ"},
+			Wordlists:   wordlist,
+			HTTPMethod:  "POST",
+			Data:        "page=FUZZ",
+			Headers:     []string{"X-Test: FUZZ"},
+			ReplayProxy: proxy.URL,
+		})
+		if err := controller.Setup(); err != nil {
+			t.Fatal(err)
+		}
+		controller.Run()
+
+		mutex.Lock()
+		defer mutex.Unlock()
+		expected := "POST " + target.URL + "/admin admin page=admin"
+		if len(replayed) != 1 || replayed[0] != expected {
+			t.Errorf("Expected replayed request %q, got %v", expected, replayed)
+		}
+	})
+
+	// 测试用例3：无效的重放代理
+	t.Run("InvalidProxy", func(t *testing.T) {
+		controller := NewController(&parse.Options{URLs: []string{target.URL}, Wordlists: wordlist, ReplayProxy: "ftp://127.0.0.1"})
+		if err := controller.Setup(); err == nil {
+			t.Error("Expected error for invalid replay proxy")
+		}
+	})
+}
+
+func TestReplayerQueueFull(t *testing.T) {
+	var failed []string
+	// 没有发送请求的goroutine，队列只能容纳一个请求
+	r := &replayer{
+		queue:   make(chan *connection.SentRequest, 1),
+		timeout: 50 * time.Millisecond,
+		onError: func(url string, err error) {
+			failed = append(failed, url)
+		},
+	}
+
+	// 队列有空位时立即加入
+	r.add(&connection.SentRequest{URL: "http://example.com/admin"})
+	if len(failed) != 0 {
+		t.Fatalf("Expected request to be queued, got failures %v", failed)
+	}
+
+	// 队列已满时等待超时后放弃，并报告被放弃的请求
+	start := time.Now()
+	r.add(&connection.SentRequest{URL: "http://example.com/backup"})
+	if elapsed := time.Since(start); elapsed < r.timeout {
+		t.Errorf("Expected add to wait for the queue, returned after %s", elapsed)
+	}
+	if len(failed) != 1 || failed[0] != "http://example.com/backup" {
+		t.Errorf("Expected dropped request to be reported, got %v", failed)
+	}
+
+	// 队列在超时前腾出空位时不放弃
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		<-r.queue
+	}()
+	r.add(&connection.SentRequest{URL: "http://example.com/login"})
+	if len(failed) != 1 {
+		t.Errorf("Expected request to wait for a free slot, got failures %v", failed)
+	}
+
+	if dropped := r.close(); dropped != 1 {
+		t.Errorf("Expected 1 dropped request, got %d", dropped)
+	}
+}
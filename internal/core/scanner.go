@@ -1,15 +1,26 @@
 package core
 
 import (
+	"net/url"
+	"regexp"
+	"strings"
+
+	"HiDir/internal/common"
 	"HiDir/internal/connection"
+	"HiDir/internal/utils"
 )
 
 // Scanner 检测响应是否为有效路径
 type Scanner struct {
-	requester *connection.Requester
-	path      string
-	context   string
-	tested    map[string]map[string]*Scanner
+	requester             *connection.Requester
+	path                  string
+	context               string
+	tested                map[string]map[string]*Scanner
+	response              *connection.Response
+	comparer              *Comparer
+	wildcardRedirectRegex string
+	threshold             float64
+	template              bool // 请求替换占位符，测试路径即为占位符的值
 }
 
 // NewScanner 创建新的Scanner实例
@@ -22,9 +33,143 @@ func NewScanner(requester *connection.Requester, path string, tested map[string]
 	}
 }
 
-// Check 检查响应是否有效
-func (s *Scanner) Check(path string, response *connection.Response) bool {
-	// 简单实现，实际应该更复杂
-	// 检查状态码、内容长度等
-	return true
+// SetThreshold 设置内容相似度阈值
+func (s *Scanner) SetThreshold(threshold float64) {
+	s.threshold = threshold
+}
+
+// SetTemplate 设置请求是否替换占位符，替换时比较前去除响应中反射的占位符的值
+func (s *Scanner) SetTemplate(template bool) {
+	s.template = template
+}
+
+// Setup 请求随机路径，学习通配符响应的特征
+func (s *Scanner) Setup() error {
+	firstPath := strings.ReplaceAll(s.path, common.WILDCARD_TEST_POINT_MARKER, utils.RandomString(12))
+	firstResponse, err := s.requester.Request(firstPath)
+	if err != nil {
+		return err
+	}
+	s.response = firstResponse
+
+	// 之前已有相同响应的测试，直接复用其结果
+	if duplicate := s.getDuplicate(firstResponse); duplicate != nil {
+		s.comparer = duplicate.comparer
+		s.wildcardRedirectRegex = duplicate.wildcardRedirectRegex
+		return nil
+	}
+
+	secondPath := strings.ReplaceAll(s.path, common.WILDCARD_TEST_POINT_MARKER, utils.RandomString(12))
+	secondResponse, err := s.requester.Request(secondPath)
+	if err != nil {
+		return err
+	}
+
+	if firstResponse.Redirect != "" && secondResponse.Redirect != "" {
+		s.wildcardRedirectRegex = generateRedirectRegex(
+			firstResponse.Redirect, firstPath,
+			secondResponse.Redirect, secondPath,
+		)
+	}
+
+	s.comparer = NewComparer(s.threshold, s.sample(firstResponse, firstPath), s.sample(secondResponse, secondPath))
+
+	return nil
+}
+
+// sample 生成比较基准，替换占位符时按响应的请求路径和占位符的值归一化，与Check一致
+func (s *Scanner) sample(response *connection.Response, path string) ComparerSample {
+	if s.template {
+		return ComparerSample{Response: response, Path: response.Path, Values: []string{path}}
+	}
+	return ComparerSample{Response: response, Path: path}
+}
+
+// Context 获取测试上下文
+func (s *Scanner) Context() string {
+	return s.context
+}
+
+// getDuplicate 查找已测试过且响应相同的Scanner
+func (s *Scanner) getDuplicate(response *connection.Response) *Scanner {
+	for _, scanners := range s.tested {
+		for _, tester := range scanners {
+			if tester == s || tester.response == nil || tester.comparer == nil {
+				continue
+			}
+			if tester.response.Status == response.Status &&
+				tester.response.Redirect == response.Redirect &&
+				tester.response.Content == response.Content {
+				return tester
+			}
+		}
+	}
+	return nil
+}
+
+// isWildcard 检查响应是否与通配符响应相似
+func (s *Scanner) isWildcard(path string, response *connection.Response, values ...string) bool {
+	return s.comparer.Similar(response, path, values...)
+}
+
+// Check 检查响应是否有效，替换占位符时values为请求中各占位符的值
+func (s *Scanner) Check(path string, response *connection.Response, values ...string) bool {
+	// 未完成测试时不做过滤
+	if s.response == nil || s.comparer == nil {
+		return true
+	}
+
+	if s.response.Status != response.Status {
+		return true
+	}
+
+	// 重定向不符合通配符规则，视为有效，替换占位符时反射的是占位符的值
+	if s.wildcardRedirectRegex != "" && response.Redirect != "" {
+		reflected := []string{path}
+		if s.template && len(values) > 0 {
+			reflected = values
+		}
+		if !s.matchRedirect(response.Redirect, reflected) {
+			return true
+		}
+	}
+
+	return !s.isWildcard(path, response, values...)
+}
+
+// matchRedirect 检查重定向是否符合通配符规则，反射的值任意一个符合即可
+func (s *Scanner) matchRedirect(redirect string, reflected []string) bool {
+	redirect = unquote(redirect)
+	for _, value := range reflected {
+		pattern := strings.ReplaceAll(s.wildcardRedirectRegex, common.REFLECTED_PATH_MARKER, regexp.QuoteMeta(unquote(value)))
+		re, err := regexp.Compile("(?i)" + pattern)
+		if err != nil || re.MatchString(redirect) {
+			return true
+		}
+	}
+	return false
+}
+
+// generateRedirectRegex 生成通配符重定向的匹配规则
+func generateRedirectRegex(firstLocation, firstPath, secondLocation, secondPath string) string {
+	firstLocation = unquote(firstLocation)
+	secondLocation = unquote(secondLocation)
+
+	// 用标记替换重定向中反射的路径
+	if firstPath != "" {
+		firstLocation = strings.ReplaceAll(firstLocation, firstPath, common.REFLECTED_PATH_MARKER)
+	}
+	if secondPath != "" {
+		secondLocation = strings.ReplaceAll(secondLocation, secondPath, common.REFLECTED_PATH_MARKER)
+	}
+
+	return utils.GenerateMatchingRegex(firstLocation, secondLocation)
+}
+
+// unquote URL解码，失败时返回原字符串
+func unquote(s string) string {
+	if decoded, err := url.PathUnescape(s); err == nil {
+		return decoded
+	}
+	return s
 }
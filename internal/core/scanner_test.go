@@ -0,0 +1,67 @@
+package core
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"HiDir/internal/common"
+	"HiDir/internal/connection"
+)
+
+func TestScannerCheck(t *testing.T) {
+	// 对任意路径都返回200并反射路径的站点
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/admin":
+			fmt.Fprint(w, "<html><body>Admin panel login form</body></html>")
+		default:
+			if len(r.URL.Path) > 4 && r.URL.Path[:4] == "/go/" {
+				http.Redirect(w, r, "/home"+r.URL.Path, http.StatusFound)
+				return
+			}
+			fmt.Fprintf(w, "<html><body>Sorry, page %s was not found</body></html>", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	requester := connection.NewRequester()
+	requester.SetURL(server.URL)
+
+	// 测试用例1：内容与通配符响应不同
+	t.Run("ContentWildcard", func(t *testing.T) {
+		tested := make(map[string]map[string]*Scanner)
+		scanner := NewScanner(requester, common.WILDCARD_TEST_POINT_MARKER, tested, "random")
+		if err := scanner.Setup(); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		for path, expected := range map[string]bool{"admin": true, "backup.zip": false, "login": false, "js": false, "api": false} {
+			response, err := requester.Request(path)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if got := scanner.Check(path, response); got != expected {
+				t.Errorf("Check(%s): expected %v, got %v", path, expected, got)
+			}
+		}
+	})
+
+	// 测试用例2：重定向与通配符规则不同
+	t.Run("RedirectWildcard", func(t *testing.T) {
+		tested := make(map[string]map[string]*Scanner)
+		scanner := NewScanner(requester, "go/"+common.WILDCARD_TEST_POINT_MARKER, tested, "random")
+		if err := scanner.Setup(); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		response, err := requester.Request("go/anything")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if scanner.Check("go/anything", response) {
+			t.Error("Expected wildcard redirect to be rejected")
+		}
+	})
+}
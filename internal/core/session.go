@@ -0,0 +1,123 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"HiDir/internal/connection"
+	"HiDir/internal/parse"
+	"HiDir/internal/report"
+)
+
+// SessionVersion 会话文件格式的版本号，格式变化时递增并在LoadSession中转换旧版本
+const SessionVersion = 1
+
+// Session 保存到会话文件中的扫描状态
+type Session struct {
+	Version           int                `json:"version"`
+	SavedAt           time.Time          `json:"saved_at"`
+	StartTime         time.Time          `json:"start_time"`
+	Options           *parse.Options     `json:"options"`      // 生效的选项
+	Targets           []string           `json:"targets"`      // 所有目标
+	TargetIndex       int                `json:"target_index"` // 正在扫描的目标
+	Directories       []SessionDirectory `json:"directories"`  // 正在扫描和等待扫描的目录，第一个为正在扫描的目录
+	Progress          FuzzerProgress     `json:"progress"`     // 正在扫描的目录的字典进度
+	PassedURLs        []string           `json:"passed_urls"`
+	Results           []*report.Result   `json:"results"`
+	Errors            int                `json:"errors"`
+	ConsecutiveErrors int                `json:"consecutive_errors"`
+}
+
+// SessionDirectory 会话中保存的目录
+type SessionDirectory struct {
+	Path   string `json:"path"`
+	Depth  int    `json:"depth"`
+	Source string `json:"source,omitempty"` // 发现该目录的结果URL
+}
+
+// LoadSession 读取会话文件
+func LoadSession(path string) (*Session, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var header struct {
+		Version int `json:"version"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, fmt.Errorf("invalid session file %s: %w", path, err)
+	}
+	if header.Version != SessionVersion {
+		return nil, fmt.Errorf("unsupported session version %d in %s (expected %d)", header.Version, path, SessionVersion)
+	}
+
+	session := &Session{}
+	if err := json.Unmarshal(data, session); err != nil {
+		return nil, fmt.Errorf("invalid session file %s: %w", path, err)
+	}
+	if session.Options == nil {
+		return nil, fmt.Errorf("invalid session file %s: missing options", path)
+	}
+	return session, nil
+}
+
+// Save 写入会话文件，先写入临时文件再替换，中断时不会损坏已有的会话
+func (s *Session) Save(path string) error {
+	s.Version = SessionVersion
+	s.SavedAt = time.Now()
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create session directory: %w", err)
+		}
+	}
+
+	temp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to save session: %w", err)
+	}
+	defer os.Remove(temp.Name())
+
+	if _, err := temp.Write(data); err != nil {
+		temp.Close()
+		return fmt.Errorf("failed to save session: %w", err)
+	}
+	if err := temp.Sync(); err != nil {
+		temp.Close()
+		return fmt.Errorf("failed to save session: %w", err)
+	}
+	if err := temp.Close(); err != nil {
+		return fmt.Errorf("failed to save session: %w", err)
+	}
+	if err := os.Rename(temp.Name(), path); err != nil {
+		return fmt.Errorf("failed to save session: %w", err)
+	}
+	return nil
+}
+
+// newSessionDirectory 转换目录以保存到会话
+func newSessionDirectory(dir *directory) SessionDirectory {
+	saved := SessionDirectory{Path: dir.Path, Depth: dir.Depth}
+	if dir.Source != nil {
+		saved.Source = dir.Source.FullPath
+	}
+	return saved
+}
+
+// restore 从会话恢复目录
+func (d SessionDirectory) restore() *directory {
+	dir := &directory{Path: d.Path, Depth: d.Depth}
+	if d.Source != "" {
+		dir.Source = &connection.Response{FullPath: d.Source}
+	}
+	return dir
+}
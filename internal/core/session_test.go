@@ -0,0 +1,178 @@
+package core
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"HiDir/internal/parse"
+)
+
+func TestSessionResume(t *testing.T) {
+	const total = 300
+
+	var (
+		mutex      sync.Mutex
+		requested  = make(map[string]int)
+		count      int
+		controller *Controller
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/")
+		mutex.Lock()
+		requested[path]++
+		if strings.HasPrefix(path, "word-") || strings.HasPrefix(path, "found-") {
+			count++
+			// 第一次扫描请求到一半时中断
+			if count == total/2 {
+				go controller.Interrupt()
+			}
+		}
+		mutex.Unlock()
+
+		if strings.HasPrefix(path, "found-") {
+			fmt.Fprint(w, "found")
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	var lines []string
+	for i := 0; i < total; i++ {
+		if i%25 == 0 {
+			lines = append(lines, fmt.Sprintf("found-%d", i))
+		} else {
+			lines = append(lines, fmt.Sprintf("word-%d", i))
+		}
+	}
+	wordlist := filepath.Join(dir, "wordlist.txt")
+	if err := os.WriteFile(wordlist, []byte(strings.Join(lines, "\n")), 0644); err != nil {
+		t.Fatal(err)
+	}
+	sessionFile := filepath.Join(dir, "scan.session")
+
+	// 测试用例1：中断后保存会话
+	controller = NewController(&parse.Options{URLs: []string{server.URL + "/"}, Wordlists: wordlist, ThreadCount: 8, SessionFile: sessionFile})
+	if err := controller.Setup(); err != nil {
+		t.Fatal(err)
+	}
+	controller.Run()
+
+	session, err := LoadSession(sessionFile)
+	if err != nil {
+		t.Fatalf("Expected session to be saved, got %v", err)
+	}
+	if session.Version != SessionVersion || len(session.Directories) != 1 || session.Progress.Position == 0 || session.Progress.Position >= total {
+		t.Fatalf("Unexpected session: version %d, directories %v, progress %+v", session.Version, session.Directories, session.Progress)
+	}
+	if session.Options.Wordlists != wordlist || session.Options.ThreadCount != 8 {
+		t.Errorf("Expected options to be saved, got %+v", session.Options)
+	}
+
+	// 测试用例2：只指定会话文件即可恢复，每个单词只请求一次
+	controller = NewController(&parse.Options{SessionFile: sessionFile})
+	if err := controller.Setup(); err != nil {
+		t.Fatal(err)
+	}
+	controller.Run()
+
+	for _, line := range lines {
+		if requested[line] != 1 {
+			t.Errorf("Expected %s to be requested once, got %d", line, requested[line])
+		}
+	}
+	if len(controller.results) != total/25 {
+		t.Errorf("Expected %d results, got %d", total/25, len(controller.results))
+	}
+	if _, err := os.Stat(sessionFile); !os.IsNotExist(err) {
+		t.Error("Expected session file to be removed after the scan completes")
+	}
+
+	// 测试用例3：不支持的版本
+	os.WriteFile(sessionFile, []byte(`{"version": 99}`), 0644)
+	if _, err := LoadSession(sessionFile); err == nil {
+		t.Error("Expected error for unsupported session version")
+	}
+}
+
+func TestSessionCheckpointDuringCalibration(t *testing.T) {
+	var (
+		mutex     sync.Mutex
+		requested = make(map[string]int)
+		failing   = true
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/")
+		mutex.Lock()
+		requested[path]++
+		fail := failing
+		mutex.Unlock()
+
+		switch {
+		case path == "admin/" && fail:
+			// 第二个目录的通配符测试请求失败
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			conn.Close()
+		case path == "admin/":
+			fmt.Fprint(w, "admin index")
+		case path == "admin":
+			http.Redirect(w, r, "/admin/", http.StatusMovedPermanently)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	lines := []string{"admin"}
+	for i := 0; i < 20; i++ {
+		lines = append(lines, fmt.Sprintf("word-%d", i))
+	}
+	wordlist := filepath.Join(dir, "wordlist.txt")
+	if err := os.WriteFile(wordlist, []byte(strings.Join(lines, "\n")), 0644); err != nil {
+		t.Fatal(err)
+	}
+	sessionFile := filepath.Join(dir, "scan.session")
+
+	// 第二个目录的通配符测试出错时保存会话，模拟随后进程被终止
+	controller := NewController(&parse.Options{URLs: []string{server.URL + "/"}, Wordlists: wordlist, ThreadCount: 4, Recursive: true})
+	if err := controller.Setup(); err != nil {
+		t.Fatal(err)
+	}
+	saved := false
+	controller.fuzzer.AddErrorCallback(func(err error) {
+		if !saved && controller.currentDirectory != nil && controller.currentDirectory.Path == "admin/" {
+			saved = controller.saveSession(sessionFile)
+			controller.Interrupt()
+		}
+	})
+	controller.Run()
+	if !saved {
+		t.Fatal("Expected the calibration error to save the session")
+	}
+
+	// 恢复后从头扫描第二个目录
+	mutex.Lock()
+	failing = false
+	mutex.Unlock()
+	controller = NewController(&parse.Options{SessionFile: sessionFile})
+	if err := controller.Setup(); err != nil {
+		t.Fatal(err)
+	}
+	controller.Run()
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	for _, line := range lines[1:] {
+		if requested["admin/"+line] == 0 {
+			t.Errorf("Expected admin/%s to be requested after resuming", line)
+		}
+	}
+}
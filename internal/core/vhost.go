@@ -0,0 +1,30 @@
+package core
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+
+	"HiDir/internal/common"
+)
+
+// vhostURL 生成虚拟主机模式下的请求URL，主机名中的占位符在请求时替换为单词
+//
+// 目标为域名时单词作为子域名，如 http://FUZZ.example.com/；目标为IP时单词即为完整的主机名。
+func vhostURL(target string) (string, error) {
+	parsed, err := url.Parse(target)
+	if err != nil || parsed.Hostname() == "" {
+		return "", fmt.Errorf("invalid target URL %q", target)
+	}
+
+	host := common.FUZZ_KEYWORD
+	if net.ParseIP(parsed.Hostname()) == nil {
+		host += "." + parsed.Hostname()
+	}
+	if port := parsed.Port(); port != "" {
+		host = net.JoinHostPort(host, port)
+	}
+	parsed.Host = host
+
+	return parsed.String(), nil
+}
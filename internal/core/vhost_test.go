@@ -0,0 +1,35 @@
+package core
+
+import "testing"
+
+func TestVhostURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		target   string
+		expected string
+		wantErr  bool
+	}{
+		// 测试用例1：域名目标，单词作为子域名
+		{"Domain", "https://example.com/app/", "https://FUZZ.example.com/app/", false},
+		// 测试用例2：保留端口
+		{"Port", "http://example.com:8080", "http://FUZZ.example.com:8080", false},
+		// 测试用例3：IP目标，单词为完整主机名
+		{"IP", "http://10.0.0.1:8000/", "http://FUZZ:8000/", false},
+		// 测试用例4：IPv6目标
+		{"IPv6", "http://[::1]/", "http://FUZZ/", false},
+		// 测试用例5：缺少协议
+		{"NoScheme", "example.com", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := vhostURL(tt.target)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
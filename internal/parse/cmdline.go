@@ -33,12 +33,14 @@ type Options struct {
 	Uppercase           bool
 	Lowercase           bool
 	Capitalization      bool
+	FuzzMode            string
 
 	// 通用设置
 	ThreadCount          int
 	Recursive            bool
 	DeepRecursive        bool
 	ForceRecursive       bool
+	Vhost                bool
 	RecursionDepth       int
 	RecursionStatusCodes string
 	Subdirs              string
@@ -50,7 +52,14 @@ type Options struct {
 	ExcludeRegex         string
 	ExcludeRedirect      string
 	ExcludeResponse      string
+	SimilarityThreshold  float64
 	SkipOnStatus         string
+	MatchWords           string
+	FilterWords          string
+	MatchLines           string
+	FilterLines          string
+	MatchTitle           string
+	FilterTitle          string
 	MinimumResponseSize  int
 	MaximumResponseSize  int
 	MaxTime              int
@@ -68,14 +77,20 @@ type Options struct {
 	AuthType        string
 	CertFile        string
 	KeyFile         string
+	CAFile          string
+	TLSMinVersion   string
+	SNI             string
+	VerifyTLS       bool
 	UserAgent       string
 	Cookie          string
 
 	// 连接设置
 	Timeout     float64
+	ReadTimeout float64
 	Delay       float64
 	Proxies     []string
 	ProxyFile   string
+	ProxyRotate string
 	ProxyAuth   string
 	ReplayProxy string
 	Tor         bool
@@ -92,6 +107,7 @@ type Options struct {
 	RedirectsHistory bool
 	Color            bool
 	Quiet            bool
+	Verbose          bool
 
 	// 输出设置
 	OutputFile   string
@@ -120,7 +136,7 @@ func ParseArguments() *Options {
 
 	// 字典设置
 	dictionary := pflag.NewFlagSet("Dictionary Settings", pflag.ExitOnError)
-	dictionary.StringVarP(&opt.Wordlists, "wordlists", "w", "", "Customize wordlists (separated by commas)")
+	dictionary.StringVarP(&opt.Wordlists, "wordlists", "w", "", "Customize wordlists (separated by commas), bind a wordlist to a placeholder with path:KEYWORD")
 	dictionary.StringVarP(&opt.Extensions, "extensions", "e", "", "Extension list separated by commas (e.g. php,asp)")
 	dictionary.BoolVarP(&opt.ForceExtensions, "force-extensions", "f", false, "Add extensions to the end of every wordlist entry")
 	dictionary.BoolVarP(&opt.OverwriteExtensions, "overwrite-extensions", "O", false, "Overwrite other extensions in the wordlist with your extensions")
@@ -131,6 +147,7 @@ func ParseArguments() *Options {
 	dictionary.BoolVarP(&opt.Uppercase, "uppercase", "U", false, "Uppercase wordlist")
 	dictionary.BoolVarP(&opt.Lowercase, "lowercase", "L", false, "Lowercase wordlist")
 	dictionary.BoolVarP(&opt.Capitalization, "capital", "C", false, "Capital wordlist")
+	dictionary.StringVar(&opt.FuzzMode, "fuzz-mode", "clusterbomb", "How to combine wordlists bound to multiple placeholders (clusterbomb, pitchfork)")
 
 	// 通用设置
 	general := pflag.NewFlagSet("General Settings", pflag.ExitOnError)
@@ -138,6 +155,7 @@ func ParseArguments() *Options {
 	general.BoolVarP(&opt.Recursive, "recursive", "r", false, "Brute-force recursively")
 	general.BoolVar(&opt.DeepRecursive, "deep-recursive", false, "Perform recursive scan on every directory depth")
 	general.BoolVar(&opt.ForceRecursive, "force-recursive", false, "Do recursive brute-force for every found path")
+	general.BoolVar(&opt.Vhost, "vhost", false, "Discover virtual hosts: use words as the Host header and SNI while connecting to --ip or the target")
 	general.IntVarP(&opt.RecursionDepth, "max-recursion-depth", "R", 0, "Maximum recursion depth")
 	general.StringVar(&opt.RecursionStatusCodes, "recursion-status", "", "Valid status codes to perform recursive scan")
 	general.StringVar(&opt.Subdirs, "subdirs", "", "Scan sub-directories of the given URL[s]")
@@ -149,7 +167,14 @@ func ParseArguments() *Options {
 	general.StringVar(&opt.ExcludeRegex, "exclude-regex", "", "Exclude responses by regular expression")
 	general.StringVar(&opt.ExcludeRedirect, "exclude-redirect", "", "Exclude responses if this regex matches redirect URL")
 	general.StringVar(&opt.ExcludeResponse, "exclude-response", "", "Exclude responses similar to response of this page")
+	general.Float64Var(&opt.SimilarityThreshold, "similarity-threshold", common.DEFAULT_SIMILARITY_THRESHOLD, "Minimum similarity (0-1) to treat a response as wildcard or as the excluded response")
 	general.StringVar(&opt.SkipOnStatus, "skip-on-status", "", "Skip target whenever hit one of these status codes")
+	general.StringVar(&opt.MatchWords, "match-words", "", "Only show responses whose word count matches (e.g. 10,20-30)")
+	general.StringVar(&opt.FilterWords, "filter-words", "", "Hide responses whose word count matches (e.g. 10,20-30)")
+	general.StringVar(&opt.MatchLines, "match-lines", "", "Only show responses whose line count matches (e.g. 10,20-30)")
+	general.StringVar(&opt.FilterLines, "filter-lines", "", "Hide responses whose line count matches (e.g. 10,20-30)")
+	general.StringVar(&opt.MatchTitle, "match-title", "", "Only show responses whose HTML title contains this text")
+	general.StringVar(&opt.FilterTitle, "filter-title", "", "Hide responses whose HTML title contains this text")
 	general.IntVar(&opt.MinimumResponseSize, "min-response-size", 0, "Minimum response length")
 	general.IntVar(&opt.MaximumResponseSize, "max-response-size", 0, "Maximum response length")
 	general.IntVar(&opt.MaxTime, "max-time", 0, "Maximum runtime for the scan")
@@ -168,22 +193,28 @@ func ParseArguments() *Options {
 	request.StringVar(&opt.AuthType, "auth-type", "", fmt.Sprintf("Authentication type (%s)", common.AUTHENTICATION_TYPES))
 	request.StringVar(&opt.CertFile, "cert-file", "", "File contains client-side certificate")
 	request.StringVar(&opt.KeyFile, "key-file", "", "File contains client-side certificate private key")
+	request.StringVar(&opt.CAFile, "ca-file", "", "File contains CA certificates to verify the server with")
+	request.StringVar(&opt.TLSMinVersion, "tls-min-version", "", "Minimum TLS version (1.0, 1.1, 1.2, 1.3)")
+	request.StringVar(&opt.SNI, "sni", "", "Server name sent in the TLS handshake")
+	request.BoolVar(&opt.VerifyTLS, "verify-tls", false, "Verify the server certificate")
 	request.StringVar(&opt.UserAgent, "user-agent", "", "User-Agent")
 	request.StringVar(&opt.Cookie, "cookie", "", "Cookie")
 
 	// 连接设置
 	connection := pflag.NewFlagSet("Connection Settings", pflag.ExitOnError)
 	connection.Float64Var(&opt.Timeout, "timeout", 0, "Connection timeout")
+	connection.Float64Var(&opt.ReadTimeout, "read-timeout", 0, "Response read timeout")
 	connection.Float64Var(&opt.Delay, "delay", 0, "Delay between requests")
 	connection.StringSliceVar(&opt.Proxies, "proxy", nil, "Proxy URL")
 	connection.StringVar(&opt.ProxyFile, "proxy-file", "", "File contains proxy servers")
+	connection.StringVar(&opt.ProxyRotate, "proxy-rotation", "round-robin", "Proxy rotation (round-robin, random)")
 	connection.StringVar(&opt.ProxyAuth, "proxy-auth", "", "Proxy authentication credential")
 	connection.StringVar(&opt.ReplayProxy, "replay-proxy", "", "Proxy to replay with found paths")
 	connection.BoolVar(&opt.Tor, "tor", false, "Use Tor network as proxy")
 	connection.StringVar(&opt.Scheme, "scheme", "", "Scheme for raw request")
 	connection.IntVar(&opt.MaxRate, "max-rate", 0, "Max requests per second")
 	connection.IntVar(&opt.MaxRetries, "retries", 0, "Number of retries for failed requests")
-	connection.StringVar(&opt.IP, "ip", "", "Server IP address")
+	connection.StringVar(&opt.IP, "ip", "", "Server IP address, connect to it instead of resolving the target")
 
 	// 高级设置
 	advanced := pflag.NewFlagSet("Advanced Settings", pflag.ExitOnError)
@@ -195,6 +226,7 @@ func ParseArguments() *Options {
 	view.BoolVar(&opt.RedirectsHistory, "redirects-history", false, "Show redirects history")
 	view.BoolVar(&opt.Color, "color", true, "Colored output")
 	view.BoolVarP(&opt.Quiet, "quiet-mode", "q", false, "Quiet mode")
+	view.BoolVarP(&opt.Verbose, "verbose", "v", false, "Show why each response was filtered out")
 
 	// 输出设置
 	output := pflag.NewFlagSet("Output Settings", pflag.ExitOnError)
@@ -0,0 +1,61 @@
+package parse
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// RangeMatcher 编译后的非负整数匹配规则，用于单词数、行数等
+//
+// 支持的写法（逗号分隔）：单个数字如 42，闭区间如 10-20。
+type RangeMatcher struct {
+	expr   string
+	ranges [][2]int
+}
+
+// ParseRanges 解析整数表达式
+func ParseRanges(expr string) (*RangeMatcher, error) {
+	matcher := &RangeMatcher{expr: strings.TrimSpace(expr)}
+
+	for _, item := range strings.Split(expr, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		bounds := strings.SplitN(item, "-", 2)
+		min, err := strconv.Atoi(strings.TrimSpace(bounds[0]))
+		if err != nil || min < 0 {
+			return nil, fmt.Errorf("invalid number %q", item)
+		}
+		max := min
+		if len(bounds) == 2 {
+			if max, err = strconv.Atoi(strings.TrimSpace(bounds[1])); err != nil || max < min {
+				return nil, fmt.Errorf("invalid range %q", item)
+			}
+		}
+		matcher.ranges = append(matcher.ranges, [2]int{min, max})
+	}
+
+	if len(matcher.ranges) == 0 {
+		return nil, fmt.Errorf("empty expression")
+	}
+
+	return matcher, nil
+}
+
+// Match 检查数字是否匹配
+func (m *RangeMatcher) Match(value int) bool {
+	for _, r := range m.ranges {
+		if value >= r[0] && value <= r[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// String 返回原始表达式
+func (m *RangeMatcher) String() string {
+	return m.expr
+}
@@ -0,0 +1,29 @@
+package parse
+
+import (
+	"testing"
+)
+
+func TestParseRanges(t *testing.T) {
+	matcher, err := ParseRanges("0, 42, 100-200")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	for _, value := range []int{0, 42, 100, 150, 200} {
+		if !matcher.Match(value) {
+			t.Errorf("Expected %d to match", value)
+		}
+	}
+	for _, value := range []int{1, 41, 99, 201} {
+		if matcher.Match(value) {
+			t.Errorf("Expected %d not to match", value)
+		}
+	}
+
+	// 无效表达式
+	for _, expr := range []string{"", "abc", "-5", "20-10", "1-x"} {
+		if _, err := ParseRanges(expr); err == nil {
+			t.Errorf("Expected error for %q", expr)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package parse
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// 请求行，如 GET /index.php HTTP/1.1
+var requestLineRegex = regexp.MustCompile(`^([A-Za-z]+) (\S+) HTTP/\d(\.\d)?$`)
+
+// 多个请求之间的分隔行，如Burp日志中的 ======
+var rawDelimiterRegex = regexp.MustCompile(`^={3,}$`)
+
+// 不从原始请求中复制的请求头，由HTTP客户端重新生成
+var skippedRawHeaders = map[string]bool{
+	"Host":              true,
+	"Content-Length":    true,
+	"Connection":        true,
+	"Transfer-Encoding": true,
+}
+
+// RawRequest 从原始HTTP请求文件中解析出的请求
+//
+// 扫描时单词追加在请求路径之后、查询字符串之前，查询字符串按原样发送。
+type RawRequest struct {
+	Method  string
+	URL     string            // 扫描目标，由协议、Host头、请求路径和查询字符串组成
+	Headers map[string]string // 不含Host、Content-Length等由客户端生成的请求头和Cookie
+	Cookie  string
+	Body    string
+}
+
+// ParseRawRequests 解析原始HTTP请求文件，scheme为空时根据端口判断协议
+//
+// 文件中可以包含多个请求，请求之间用 === 行分隔，或者在上一个请求结束后
+// 空一行直接写下一个请求行。分隔行之间不是请求的内容（如Burp日志中的时间和地址）会被忽略。
+func ParseRawRequests(content, scheme string) ([]*RawRequest, error) {
+	// 按分隔行切分，行尾的\r保留到读取请求体时处理，使Content-Length按原始字节计算
+	var blocks [][]string
+	var block []string
+	for _, line := range strings.Split(content, "\n") {
+		if rawDelimiterRegex.MatchString(strings.TrimSpace(line)) {
+			blocks = append(blocks, block)
+			block = nil
+			continue
+		}
+		block = append(block, line)
+	}
+	blocks = append(blocks, block)
+
+	var requests []*RawRequest
+	for _, lines := range blocks {
+		for len(lines) > 0 {
+			// 跳过空行，块中没有请求行时忽略整个块
+			if strings.TrimSpace(lines[0]) == "" {
+				lines = lines[1:]
+				continue
+			}
+			if !requestLineRegex.MatchString(strings.TrimSpace(lines[0])) {
+				break
+			}
+
+			request, rest, err := parseRawRequest(lines, scheme)
+			if err != nil {
+				return nil, err
+			}
+			requests = append(requests, request)
+			lines = rest
+		}
+	}
+
+	if len(requests) == 0 {
+		return nil, fmt.Errorf("no HTTP request found in raw request file")
+	}
+	return requests, nil
+}
+
+// parseRawRequest 从lines开头解析一个请求，返回剩余的行
+func parseRawRequest(lines []string, scheme string) (*RawRequest, []string, error) {
+	match := requestLineRegex.FindStringSubmatch(strings.TrimSpace(lines[0]))
+	request := &RawRequest{
+		Method:  strings.ToUpper(match[1]),
+		Headers: make(map[string]string),
+	}
+	target := match[2]
+
+	// 请求头
+	var host string
+	contentLength := -1
+	i := 1
+	for ; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if line == "" {
+			i++
+			break
+		}
+
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 {
+			return nil, nil, fmt.Errorf("invalid header line %q in raw request", line)
+		}
+		key, value := http.CanonicalHeaderKey(strings.TrimSpace(parts[0])), strings.TrimSpace(parts[1])
+		switch key {
+		case "Host":
+			host = value
+		case "Content-Length":
+			contentLength, _ = strconv.Atoi(value)
+		case "Cookie":
+			request.Cookie = value
+		}
+		if !skippedRawHeaders[key] && key != "Cookie" {
+			request.Headers[key] = value
+		}
+	}
+
+	// 请求体，有Content-Length时按长度读取，否则读到下一个请求行为止
+	body, rest := lines[i:], []string(nil)
+	for j := 0; j < len(body); j++ {
+		// 请求头后的空行也可以作为上一个请求的结尾
+		if (j == 0 || strings.TrimSpace(body[j-1]) == "") && requestLineRegex.MatchString(strings.TrimSpace(body[j])) {
+			body, rest = body[:j], body[j:]
+			break
+		}
+	}
+	raw := strings.Join(body, "\n")
+	if contentLength >= 0 && contentLength < len(raw) {
+		raw = raw[:contentLength]
+	} else {
+		raw = strings.TrimRight(raw, "\r\n")
+	}
+	request.Body = strings.ReplaceAll(raw, "\r\n", "\n")
+
+	var err error
+	if request.URL, err = rawRequestURL(target, host, scheme); err != nil {
+		return nil, nil, err
+	}
+
+	return request, rest, nil
+}
+
+// rawRequestURL 根据请求行中的路径和Host头生成扫描目标
+func rawRequestURL(target, host, scheme string) (string, error) {
+	parsed, err := url.Parse(target)
+	if err != nil {
+		return "", fmt.Errorf("invalid request target %q in raw request: %w", target, err)
+	}
+
+	// 请求行中为完整URL时（代理请求）直接使用
+	if parsed.Host == "" {
+		if host == "" {
+			return "", fmt.Errorf("missing Host header in raw request")
+		}
+		parsed.Host = host
+	}
+	if scheme != "" {
+		parsed.Scheme = strings.ToLower(scheme)
+	} else if parsed.Scheme == "" {
+		port, _ := strconv.Atoi(parsed.Port())
+		parsed.Scheme = DetectScheme(parsed.Hostname(), port)
+	}
+
+	// 保留查询字符串，片段不会发送到服务器
+	parsed.Fragment = ""
+	if parsed.Path == "" {
+		parsed.Path = "/"
+	}
+
+	return parsed.String(), nil
+}
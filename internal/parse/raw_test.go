@@ -0,0 +1,102 @@
+package parse
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseRawRequests(t *testing.T) {
+	// 测试用例1：带请求体和Cookie的请求
+	t.Run("SingleRequest", func(t *testing.T) {
+		content := "POST /api/users?id=1 HTTP/1.1\r\n" +
+			"Host: example.com:8443\r\n" +
+			"content-type: application/x-www-form-urlencoded\r\n" +
+			"Cookie: session=abc; lang=en\r\n" +
+			"Authorization: Bearer token\r\n" +
+			"Content-Length: 14\r\n" +
+			"Connection: close\r\n" +
+			"\r\n" +
+			"name=admin&x=1"
+
+		requests, err := ParseRawRequests(content, "https")
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := &RawRequest{
+			Method: "POST",
+			URL:    "https://example.com:8443/api/users?id=1",
+			Headers: map[string]string{
+				"Content-Type":  "application/x-www-form-urlencoded",
+				"Authorization": "Bearer token",
+			},
+			Cookie: "session=abc; lang=en",
+			Body:   "name=admin&x=1",
+		}
+		if len(requests) != 1 || !reflect.DeepEqual(requests[0], expected) {
+			t.Errorf("Expected %+v, got %+v", expected, requests)
+		}
+	})
+
+	// 测试用例2：CRLF换行的请求体按原始字节的Content-Length读取
+	t.Run("CRLFBody", func(t *testing.T) {
+		content := "POST /form HTTP/1.1\r\n" +
+			"Host: example.com\r\n" +
+			"Content-Length: 8\r\n" +
+			"\r\n" +
+			"a=1\r\nb=2\r\n" +
+			"trailing data\r\n"
+
+		requests, err := ParseRawRequests(content, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(requests) != 1 || requests[0].Body != "a=1\nb=2" {
+			t.Errorf("Expected body %q, got %+v", "a=1\nb=2", requests)
+		}
+	})
+
+	// 测试用例3：多个请求
+	t.Run("MultipleRequests", func(t *testing.T) {
+		content := "======================================================\n" +
+			"10:00:00 AM  https://example.com:443  [93.184.216.34]\n" +
+			"======================================================\n" +
+			"GET /admin/ HTTP/1.1\n" +
+			"Host: example.com:443\n" +
+			"\n" +
+			"\n" +
+			"======================================================\n" +
+			"\n" +
+			"GET http://test.com/api HTTP/1.1\n" +
+			"Host: ignored.com\n" +
+			"\n" +
+			"get /v2 HTTP/2\n" +
+			"Host: test.com\n"
+
+		requests, err := ParseRawRequests(content, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var urls []string
+		for _, request := range requests {
+			urls = append(urls, request.Method+" "+request.URL)
+		}
+		expected := []string{"GET https://example.com:443/admin/", "GET http://test.com/api", "GET http://test.com/v2"}
+		if !reflect.DeepEqual(urls, expected) {
+			t.Errorf("Expected %v, got %v", expected, urls)
+		}
+	})
+
+	// 测试用例4：无效的请求
+	t.Run("Invalid", func(t *testing.T) {
+		for _, content := range []string{
+			"",
+			"not a request",
+			"GET / HTTP/1.1\nUser-Agent: test\n",
+			"GET / HTTP/1.1\nHost: example.com\ninvalid header\n",
+		} {
+			if _, err := ParseRawRequests(content, ""); err == nil {
+				t.Errorf("Expected error for %q", content)
+			}
+		}
+	})
+}
@@ -0,0 +1,93 @@
+package parse
+
+import (
+	"fmt"
+	"strings"
+
+	"HiDir/internal/utils"
+)
+
+// SizeMatcher 编译后的响应大小匹配规则
+//
+// 支持的写法（逗号分隔）：
+//
+//	0B, 512     精确的字节数
+//	4KB, 1.5MB  与输出中显示的大小相同，即 utils.HumanSize 结果一致
+//	1KB-2KB     按字节计算的闭区间
+type SizeMatcher struct {
+	expr   string
+	exact  map[int64]bool
+	human  map[string]bool
+	ranges [][2]int64
+}
+
+// ParseSizes 解析响应大小表达式
+func ParseSizes(expr string) (*SizeMatcher, error) {
+	matcher := &SizeMatcher{
+		expr:  strings.TrimSpace(expr),
+		exact: make(map[int64]bool),
+		human: make(map[string]bool),
+	}
+
+	for _, item := range strings.Split(expr, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		// 范围
+		if bounds := strings.SplitN(item, "-", 2); len(bounds) == 2 {
+			min, err := utils.ParseHumanSize(bounds[0])
+			if err != nil {
+				return nil, err
+			}
+			max, err := utils.ParseHumanSize(bounds[1])
+			if err != nil {
+				return nil, err
+			}
+			if min > max {
+				return nil, fmt.Errorf("invalid size range %q: start is greater than end", item)
+			}
+			matcher.ranges = append(matcher.ranges, [2]int64{min, max})
+			continue
+		}
+
+		size, err := utils.ParseHumanSize(item)
+		if err != nil {
+			return nil, err
+		}
+		if size < 1024 {
+			matcher.exact[size] = true
+		} else {
+			matcher.human[utils.HumanSize(size)] = true
+		}
+	}
+
+	return matcher, nil
+}
+
+// Match 检查响应大小是否匹配
+func (m *SizeMatcher) Match(size int64) bool {
+	if m.exact[size] {
+		return true
+	}
+	if len(m.human) > 0 && m.human[utils.HumanSize(size)] {
+		return true
+	}
+	for _, r := range m.ranges {
+		if size >= r[0] && size <= r[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// IsEmpty 检查是否没有任何规则
+func (m *SizeMatcher) IsEmpty() bool {
+	return len(m.exact) == 0 && len(m.human) == 0 && len(m.ranges) == 0
+}
+
+// String 返回原始表达式
+func (m *SizeMatcher) String() string {
+	return m.expr
+}
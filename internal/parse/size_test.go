@@ -0,0 +1,48 @@
+package parse
+
+import (
+	"testing"
+)
+
+func TestParseSizes(t *testing.T) {
+	tests := []struct {
+		expr     string
+		match    []int64
+		notMatch []int64
+	}{
+		{expr: "0B", match: []int64{0}, notMatch: []int64{1}},
+		{expr: "0,512", match: []int64{0, 512}, notMatch: []int64{511, 513}},
+		{expr: "4KB", match: []int64{4096, 4100}, notMatch: []int64{3000, 5000}},
+		{expr: "1.5MB", match: []int64{1572864}, notMatch: []int64{1048576}},
+		{expr: "1KB-2KB", match: []int64{1024, 1500, 2048}, notMatch: []int64{1023, 2049}},
+		{expr: "0B, 100-200", match: []int64{0, 100, 200}, notMatch: []int64{50, 201}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			matcher, err := ParseSizes(tt.expr)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			for _, size := range tt.match {
+				if !matcher.Match(size) {
+					t.Errorf("Expected %d to match %q", size, tt.expr)
+				}
+			}
+			for _, size := range tt.notMatch {
+				if matcher.Match(size) {
+					t.Errorf("Expected %d not to match %q", size, tt.expr)
+				}
+			}
+		})
+	}
+
+	// 无效表达式
+	for _, expr := range []string{"abc", "4XB", "2KB-1KB", "-1", "KB", "9999999999T", "8EB", "1KB-9999999999T", "Inf", "NaN"} {
+		t.Run("Invalid_"+expr, func(t *testing.T) {
+			if _, err := ParseSizes(expr); err == nil {
+				t.Errorf("Expected error for %q", expr)
+			}
+		})
+	}
+}
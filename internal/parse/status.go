@@ -0,0 +1,140 @@
+package parse
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// 状态码的取值范围
+const (
+	minStatusCode = 100
+	maxStatusCode = 999
+)
+
+// StatusMatcher 编译后的状态码匹配规则
+//
+// 支持的写法（逗号分隔）：
+//
+//	200         单个状态码
+//	200-299     范围
+//	4xx, 30x    以x作为通配符
+//	!404        排除，可与以上写法组合，如 !500-599
+//
+// 只有排除项时匹配除排除项以外的所有状态码。
+type StatusMatcher struct {
+	expr  string
+	table [maxStatusCode + 1]bool
+	empty bool
+}
+
+// ParseStatusCodes 解析状态码表达式，空表达式返回匹配所有状态码的规则
+func ParseStatusCodes(expr string) (*StatusMatcher, error) {
+	matcher := &StatusMatcher{expr: strings.TrimSpace(expr)}
+
+	var include, exclude [][2]int
+	for _, item := range strings.Split(expr, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		negated := strings.HasPrefix(item, "!")
+		min, max, err := parseStatusItem(strings.TrimSpace(strings.TrimPrefix(item, "!")))
+		if err != nil {
+			return nil, fmt.Errorf("invalid status code expression %q: %w", item, err)
+		}
+
+		if negated {
+			exclude = append(exclude, [2]int{min, max})
+		} else {
+			include = append(include, [2]int{min, max})
+		}
+	}
+
+	matcher.empty = len(include) == 0 && len(exclude) == 0
+
+	// 没有包含项时默认包含所有状态码
+	if len(include) == 0 {
+		include = append(include, [2]int{minStatusCode, maxStatusCode})
+	}
+	for _, r := range include {
+		for status := r[0]; status <= r[1]; status++ {
+			matcher.table[status] = true
+		}
+	}
+	for _, r := range exclude {
+		for status := r[0]; status <= r[1]; status++ {
+			matcher.table[status] = false
+		}
+	}
+
+	return matcher, nil
+}
+
+// parseStatusItem 解析单个状态码、范围或通配符，返回闭区间
+func parseStatusItem(item string) (int, int, error) {
+	// 通配符，如 4xx、30x
+	if len(item) == 3 && strings.ContainsAny(item, "xX") {
+		lower := strings.ToLower(item)
+		wildcard := strings.Index(lower, "x")
+		if strings.Trim(lower[wildcard:], "x") != "" {
+			return 0, 0, fmt.Errorf("wildcard must be at the end")
+		}
+		min, err := parseStatusCode(strings.ReplaceAll(lower, "x", "0"))
+		if err != nil {
+			return 0, 0, err
+		}
+		max, _ := strconv.Atoi(strings.ReplaceAll(lower, "x", "9"))
+		return min, max, nil
+	}
+
+	// 范围，如 200-299
+	if bounds := strings.SplitN(item, "-", 2); len(bounds) == 2 {
+		min, err := parseStatusCode(strings.TrimSpace(bounds[0]))
+		if err != nil {
+			return 0, 0, err
+		}
+		max, err := parseStatusCode(strings.TrimSpace(bounds[1]))
+		if err != nil {
+			return 0, 0, err
+		}
+		if min > max {
+			return 0, 0, fmt.Errorf("range start is greater than end")
+		}
+		return min, max, nil
+	}
+
+	status, err := parseStatusCode(item)
+	return status, status, err
+}
+
+// parseStatusCode 解析单个状态码
+func parseStatusCode(value string) (int, error) {
+	status, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a number", value)
+	}
+	if status < minStatusCode || status > maxStatusCode {
+		return 0, fmt.Errorf("%d is out of range %d-%d", status, minStatusCode, maxStatusCode)
+	}
+	return status, nil
+}
+
+// Match 检查状态码是否匹配
+func (m *StatusMatcher) Match(status int) bool {
+	if status < 0 || status > maxStatusCode {
+		return false
+	}
+	return m.table[status]
+}
+
+// IsEmpty 检查是否由空表达式生成
+func (m *StatusMatcher) IsEmpty() bool {
+	return m.empty
+}
+
+// String 返回原始表达式
+func (m *StatusMatcher) String() string {
+	return m.expr
+}
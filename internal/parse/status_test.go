@@ -0,0 +1,50 @@
+package parse
+
+import (
+	"testing"
+)
+
+func TestParseStatusCodes(t *testing.T) {
+	tests := []struct {
+		expr     string
+		match    []int
+		notMatch []int
+	}{
+		{expr: "", match: []int{200, 404, 500}},
+		{expr: "200", match: []int{200}, notMatch: []int{201, 404}},
+		{expr: "200-299,301", match: []int{200, 250, 299, 301}, notMatch: []int{300, 302, 404}},
+		{expr: "4xx", match: []int{400, 403, 499}, notMatch: []int{399, 500}},
+		{expr: "30X", match: []int{300, 302, 309}, notMatch: []int{310}},
+		{expr: "!404", match: []int{200, 403, 500}, notMatch: []int{404}},
+		{expr: "200-299,301,4xx,!404", match: []int{200, 301, 403}, notMatch: []int{302, 404, 500}},
+		{expr: "!500-599, !429", match: []int{200, 404}, notMatch: []int{429, 500, 503}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			matcher, err := ParseStatusCodes(tt.expr)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			for _, status := range tt.match {
+				if !matcher.Match(status) {
+					t.Errorf("Expected %d to match %q", status, tt.expr)
+				}
+			}
+			for _, status := range tt.notMatch {
+				if matcher.Match(status) {
+					t.Errorf("Expected %d not to match %q", status, tt.expr)
+				}
+			}
+		})
+	}
+
+	// 无效表达式
+	for _, expr := range []string{"abc", "200-", "299-200", "4x4", "1000", "99", "!"} {
+		t.Run("Invalid_"+expr, func(t *testing.T) {
+			if _, err := ParseStatusCodes(expr); err == nil {
+				t.Errorf("Expected error for %q", expr)
+			}
+		})
+	}
+}
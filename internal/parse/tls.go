@@ -0,0 +1,29 @@
+package parse
+
+import (
+	"crypto/tls"
+	"fmt"
+	"strings"
+)
+
+// TLS版本名称
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// ParseTLSVersion 解析TLS版本，如 1.2、TLS1.2、tlsv1.3，空字符串表示不限制
+func ParseTLSVersion(version string) (uint16, error) {
+	name := strings.ToLower(strings.TrimSpace(version))
+	if name == "" {
+		return 0, nil
+	}
+	name = strings.TrimPrefix(strings.TrimPrefix(name, "tls"), "v")
+
+	if value, ok := tlsVersions[name]; ok {
+		return value, nil
+	}
+	return 0, fmt.Errorf("invalid TLS version %q, expected one of 1.0, 1.1, 1.2, 1.3", version)
+}
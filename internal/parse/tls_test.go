@@ -0,0 +1,36 @@
+package parse
+
+import (
+	"crypto/tls"
+	"testing"
+)
+
+func TestParseTLSVersion(t *testing.T) {
+	// 测试用例1：支持的写法
+	t.Run("Valid", func(t *testing.T) {
+		for version, expected := range map[string]uint16{
+			"":        0,
+			"1.0":     tls.VersionTLS10,
+			"1.2":     tls.VersionTLS12,
+			"TLS1.3":  tls.VersionTLS13,
+			"tlsv1.1": tls.VersionTLS11,
+		} {
+			got, err := ParseTLSVersion(version)
+			if err != nil {
+				t.Errorf("Expected no error for %q, got %v", version, err)
+			}
+			if got != expected {
+				t.Errorf("Expected %x for %q, got %x", expected, version, got)
+			}
+		}
+	})
+
+	// 测试用例2：无效的版本
+	t.Run("Invalid", func(t *testing.T) {
+		for _, version := range []string{"1.4", "ssl3", "2"} {
+			if _, err := ParseTLSVersion(version); err == nil {
+				t.Errorf("Expected error for %q", version)
+			}
+		}
+	})
+}
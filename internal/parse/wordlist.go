@@ -0,0 +1,38 @@
+package parse
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// 占位符名称，如 FUZZ、W1
+var keywordRegex = regexp.MustCompile(`^[A-Z][A-Z0-9_]*$`)
+
+// Wordlist 字典文件及其绑定的占位符
+type Wordlist struct {
+	Path    string
+	Keyword string // 未指定时为空
+}
+
+// ParseWordlists 解析逗号分隔的字典列表，每项可以用 路径:占位符 的形式绑定占位符，如 users.txt:W1
+func ParseWordlists(value string) ([]Wordlist, error) {
+	var wordlists []Wordlist
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		wordlist := Wordlist{Path: item}
+		if i := strings.LastIndex(item, ":"); i >= 0 && keywordRegex.MatchString(item[i+1:]) {
+			wordlist = Wordlist{Path: item[:i], Keyword: item[i+1:]}
+		}
+		if wordlist.Path == "" {
+			return nil, fmt.Errorf("missing wordlist path in %q", item)
+		}
+		wordlists = append(wordlists, wordlist)
+	}
+
+	return wordlists, nil
+}
@@ -0,0 +1,33 @@
+package parse
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseWordlists(t *testing.T) {
+	// 测试用例1：绑定占位符
+	t.Run("Keywords", func(t *testing.T) {
+		wordlists, err := ParseWordlists("dict/dicc.txt, users.txt:W1,pass.txt:W2 ,C:\\dict.txt,vhosts.txt:HOST_NAME")
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := []Wordlist{
+			{Path: "dict/dicc.txt"},
+			{Path: "users.txt", Keyword: "W1"},
+			{Path: "pass.txt", Keyword: "W2"},
+			{Path: "C:\\dict.txt"},
+			{Path: "vhosts.txt", Keyword: "HOST_NAME"},
+		}
+		if !reflect.DeepEqual(wordlists, expected) {
+			t.Errorf("Expected %v, got %v", expected, wordlists)
+		}
+	})
+
+	// 测试用例2：缺少路径
+	t.Run("MissingPath", func(t *testing.T) {
+		if _, err := ParseWordlists("users.txt:W1,:W2"); err == nil {
+			t.Error("Expected error for missing path")
+		}
+	})
+}
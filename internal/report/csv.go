@@ -0,0 +1,65 @@
+package report
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+)
+
+// CSV表头
+var csvHeader = []string{"URL", "Status", "Size", "Content Type", "Redirection", "Depth", "Source", "Words", "Lines", "Title", "Body Hash", "Time"}
+
+// CSVWriter CSV格式
+type CSVWriter struct {
+	diff bool // 是否为差异报告，差异报告多一列变化
+}
+
+// Begin 写入报告开头
+func (cw *CSVWriter) Begin(w io.Writer, info *Info) error {
+	cw.diff = info.Baseline != ""
+	if cw.diff {
+		return writeCSVRecord(w, append([]string{"Change"}, csvHeader...))
+	}
+	return writeCSVRecord(w, csvHeader)
+}
+
+// WriteResult 追加一个结果
+func (cw *CSVWriter) WriteResult(w io.Writer, result *Result) error {
+	if cw.diff {
+		return writeCSVRecord(w, append([]string{changeText(result.Change)}, csvRecord(result)...))
+	}
+	return writeCSVRecord(w, csvRecord(result))
+}
+
+// End 写入报告结尾
+func (cw *CSVWriter) End(w io.Writer, info *Info) error {
+	return nil
+}
+
+// writeCSVRecord 写入一行CSV
+func writeCSVRecord(w io.Writer, record []string) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(record); err != nil {
+		return err
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// csvRecord 将结果转换为CSV行
+func csvRecord(result *Result) []string {
+	return []string{
+		result.URL,
+		strconv.Itoa(result.Status),
+		strconv.FormatInt(result.Size, 10),
+		result.ContentType,
+		result.Redirect,
+		strconv.Itoa(result.Depth),
+		result.Source,
+		strconv.Itoa(result.Words),
+		strconv.Itoa(result.Lines),
+		result.Title,
+		result.BodyHash,
+		result.Time.Format("2006-01-02T15:04:05Z07:00"),
+	}
+}
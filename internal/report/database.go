@@ -0,0 +1,335 @@
+package report
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// 数据库表结构，扫描、目标、结果和响应信息分表保存
+const databaseSchema = `
+CREATE TABLE IF NOT EXISTS scans (
+	id         INTEGER PRIMARY KEY AUTOINCREMENT,
+	version    TEXT NOT NULL,
+	command    TEXT NOT NULL,
+	method     TEXT NOT NULL,
+	wordlists  TEXT NOT NULL,
+	options    TEXT NOT NULL,
+	start_time TEXT NOT NULL,
+	end_time   TEXT
+);
+CREATE TABLE IF NOT EXISTS targets (
+	id  INTEGER PRIMARY KEY AUTOINCREMENT,
+	url TEXT NOT NULL UNIQUE
+);
+CREATE TABLE IF NOT EXISTS scan_targets (
+	scan_id   INTEGER NOT NULL REFERENCES scans(id) ON DELETE CASCADE,
+	target_id INTEGER NOT NULL REFERENCES targets(id),
+	position  INTEGER NOT NULL,
+	PRIMARY KEY (scan_id, target_id)
+);
+CREATE TABLE IF NOT EXISTS findings (
+	id        INTEGER PRIMARY KEY AUTOINCREMENT,
+	scan_id   INTEGER NOT NULL REFERENCES scans(id) ON DELETE CASCADE,
+	target_id INTEGER NOT NULL REFERENCES targets(id),
+	url       TEXT NOT NULL,
+	path      TEXT NOT NULL,
+	status    INTEGER NOT NULL,
+	depth     INTEGER NOT NULL,
+	source    TEXT NOT NULL,
+	time      TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS findings_scan ON findings(scan_id);
+CREATE INDEX IF NOT EXISTS findings_target_path ON findings(target_id, path);
+CREATE TABLE IF NOT EXISTS responses (
+	finding_id   INTEGER PRIMARY KEY REFERENCES findings(id) ON DELETE CASCADE,
+	size         INTEGER NOT NULL,
+	content_type TEXT NOT NULL,
+	redirect     TEXT NOT NULL,
+	words        INTEGER NOT NULL,
+	lines        INTEGER NOT NULL,
+	title        TEXT NOT NULL,
+	body_hash    TEXT NOT NULL
+);
+`
+
+// 数据库中时间的保存格式
+const databaseTimeFormat = time.RFC3339Nano
+
+// Database SQLite结果数据库，多次扫描的结果保存在同一个数据库中
+type Database struct {
+	db *sql.DB
+}
+
+// ScanSummary 数据库中一次扫描的概要
+type ScanSummary struct {
+	ID        int64
+	Command   string
+	Targets   []string
+	StartTime time.Time
+	EndTime   time.Time // 扫描被中断时为零值
+	Findings  int
+}
+
+// OpenDatabase 打开数据库，不存在时创建
+func OpenDatabase(path string) (*Database, error) {
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create database directory: %w", err)
+		}
+	}
+
+	db, err := sql.Open("sqlite", databaseDSN(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database %s: %w", path, err)
+	}
+	if _, err := db.Exec(databaseSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize database %s: %w", path, err)
+	}
+
+	return &Database{db: db}, nil
+}
+
+// databaseDSN 生成数据库连接串，路径按URI转义，避免其中的?、#和%被当作参数或片段
+func databaseDSN(path string) string {
+	dsn := url.URL{
+		Scheme:   "file",
+		Opaque:   (&url.URL{Path: path}).EscapedPath(),
+		RawQuery: "_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)",
+	}
+	return dsn.String()
+}
+
+// Close 关闭数据库
+func (d *Database) Close() error {
+	return d.db.Close()
+}
+
+// StartScan 记录一次新的扫描，返回扫描ID
+func (d *Database) StartScan(info *Info) (int64, error) {
+	options, err := json.Marshal(info.Options)
+	if err != nil {
+		return 0, err
+	}
+
+	tx, err := d.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec("INSERT INTO scans (version, command, method, wordlists, options, start_time) VALUES (?, ?, ?, ?, ?, ?)",
+		info.Version, info.Command, info.Method, strings.Join(info.Wordlists, ","), string(options), info.StartTime.Format(databaseTimeFormat))
+	if err != nil {
+		return 0, err
+	}
+	scanID, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	for i, target := range info.Targets {
+		targetID, err := targetID(tx, target)
+		if err != nil {
+			return 0, err
+		}
+		if _, err := tx.Exec("INSERT OR IGNORE INTO scan_targets (scan_id, target_id, position) VALUES (?, ?, ?)", scanID, targetID, i); err != nil {
+			return 0, err
+		}
+	}
+
+	return scanID, tx.Commit()
+}
+
+// AddResult 添加一个扫描结果
+func (d *Database) AddResult(scanID int64, result *Result) error {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	targetID, err := targetID(tx, result.Target)
+	if err != nil {
+		return err
+	}
+
+	res, err := tx.Exec("INSERT INTO findings (scan_id, target_id, url, path, status, depth, source, time) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+		scanID, targetID, result.URL, result.Path, result.Status, result.Depth, result.Source, result.Time.Format(databaseTimeFormat))
+	if err != nil {
+		return err
+	}
+	findingID, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("INSERT INTO responses (finding_id, size, content_type, redirect, words, lines, title, body_hash) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+		findingID, result.Size, result.ContentType, result.Redirect, result.Words, result.Lines, result.Title, result.BodyHash)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// FinishScan 记录扫描的结束时间
+func (d *Database) FinishScan(scanID int64, endTime time.Time) error {
+	_, err := d.db.Exec("UPDATE scans SET end_time = ? WHERE id = ?", endTime.Format(databaseTimeFormat), scanID)
+	return err
+}
+
+// Scans 列出所有扫描，按开始时间排序
+func (d *Database) Scans() ([]*ScanSummary, error) {
+	rows, err := d.db.Query(`
+		SELECT s.id, s.command, s.start_time, COALESCE(s.end_time, ''),
+			(SELECT COUNT(*) FROM findings f WHERE f.scan_id = s.id)
+		FROM scans s ORDER BY s.start_time, s.id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	scans := make([]*ScanSummary, 0)
+	for rows.Next() {
+		scan := &ScanSummary{}
+		var startTime, endTime string
+		if err := rows.Scan(&scan.ID, &scan.Command, &startTime, &endTime, &scan.Findings); err != nil {
+			return nil, err
+		}
+		scan.StartTime, _ = time.Parse(databaseTimeFormat, startTime)
+		scan.EndTime, _ = time.Parse(databaseTimeFormat, endTime)
+		scans = append(scans, scan)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, scan := range scans {
+		if scan.Targets, err = d.scanTargets(scan.ID); err != nil {
+			return nil, err
+		}
+	}
+	return scans, nil
+}
+
+// LoadScan 读取一次扫描的完整报告
+func (d *Database) LoadScan(scanID int64) (*Report, error) {
+	info := &Info{}
+	var wordlists, options, startTime, endTime string
+	err := d.db.QueryRow("SELECT version, command, method, wordlists, options, start_time, COALESCE(end_time, '') FROM scans WHERE id = ?", scanID).
+		Scan(&info.Version, &info.Command, &info.Method, &wordlists, &options, &startTime, &endTime)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("scan %d not found", scanID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if wordlists != "" {
+		info.Wordlists = strings.Split(wordlists, ",")
+	}
+	if err := json.Unmarshal([]byte(options), &info.Options); err != nil {
+		return nil, fmt.Errorf("invalid options of scan %d: %w", scanID, err)
+	}
+	info.StartTime, _ = time.Parse(databaseTimeFormat, startTime)
+	info.EndTime, _ = time.Parse(databaseTimeFormat, endTime)
+	if info.Targets, err = d.scanTargets(scanID); err != nil {
+		return nil, err
+	}
+
+	rows, err := d.db.Query(`
+		SELECT t.url, f.url, f.path, f.status, f.depth, f.source, f.time,
+			r.size, r.content_type, r.redirect, r.words, r.lines, r.title, r.body_hash
+		FROM findings f
+		JOIN targets t ON t.id = f.target_id
+		JOIN responses r ON r.finding_id = f.id
+		WHERE f.scan_id = ? ORDER BY f.id`, scanID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	report := &Report{Info: info, Results: make([]*Result, 0)}
+	for rows.Next() {
+		result := &Result{}
+		var resultTime string
+		err := rows.Scan(&result.Target, &result.URL, &result.Path, &result.Status, &result.Depth, &result.Source, &resultTime,
+			&result.Size, &result.ContentType, &result.Redirect, &result.Words, &result.Lines, &result.Title, &result.BodyHash)
+		if err != nil {
+			return nil, err
+		}
+		result.Time, _ = time.Parse(databaseTimeFormat, resultTime)
+		report.Results = append(report.Results, result)
+	}
+	return report, rows.Err()
+}
+
+// scanTargets 获取扫描的目标列表
+func (d *Database) scanTargets(scanID int64) ([]string, error) {
+	rows, err := d.db.Query("SELECT t.url FROM scan_targets st JOIN targets t ON t.id = st.target_id WHERE st.scan_id = ? ORDER BY st.position", scanID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	targets := make([]string, 0)
+	for rows.Next() {
+		var target string
+		if err := rows.Scan(&target); err != nil {
+			return nil, err
+		}
+		targets = append(targets, target)
+	}
+	return targets, rows.Err()
+}
+
+// targetID 获取目标的ID，不存在时创建
+func targetID(tx *sql.Tx, target string) (int64, error) {
+	if _, err := tx.Exec("INSERT OR IGNORE INTO targets (url) VALUES (?)", target); err != nil {
+		return 0, err
+	}
+	var id int64
+	err := tx.QueryRow("SELECT id FROM targets WHERE url = ?", target).Scan(&id)
+	return id, err
+}
+
+// databaseOutput 将结果写入数据库中的一次扫描
+type databaseOutput struct {
+	path   string
+	db     *Database
+	scanID int64
+}
+
+// Path 获取数据库路径
+func (o *databaseOutput) Path() string {
+	return o.path
+}
+
+// Add 追加一个结果
+func (o *databaseOutput) Add(result *Result) error {
+	if err := o.db.AddResult(o.scanID, result); err != nil {
+		return fmt.Errorf("failed to write database %s: %w", o.path, err)
+	}
+	return nil
+}
+
+// Close 记录结束时间并关闭数据库
+func (o *databaseOutput) Close(endTime time.Time) error {
+	err := o.db.FinishScan(o.scanID, endTime)
+	if closeErr := o.db.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write database %s: %w", o.path, err)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package report
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDatabase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results.db")
+	report := newTestReport()
+
+	// 测试用例1：通过Open写入两次扫描，目标只保存一次
+	for i := 0; i < 2; i++ {
+		output, err := Open(path, "sqlite", report.Info)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		for _, result := range report.Results {
+			if err := output.Add(result); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+		}
+		if i == 0 {
+			if err := output.Close(report.Info.EndTime); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+		} else {
+			// 模拟被中断的扫描，只关闭数据库
+			output.(*databaseOutput).db.Close()
+		}
+	}
+
+	db, err := OpenDatabase(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer db.Close()
+
+	var targets int
+	db.db.QueryRow("SELECT COUNT(*) FROM targets").Scan(&targets)
+	if targets != 1 {
+		t.Errorf("Expected 1 target, got %d", targets)
+	}
+
+	// 测试用例2：列出扫描
+	scans, err := db.Scans()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(scans) != 2 {
+		t.Fatalf("Expected 2 scans, got %d", len(scans))
+	}
+	if scans[0].Findings != 2 || scans[0].EndTime.IsZero() || len(scans[0].Targets) != 1 {
+		t.Errorf("Unexpected first scan: %+v", scans[0])
+	}
+	if !scans[1].EndTime.IsZero() {
+		t.Errorf("Expected interrupted scan to have no end time, got %v", scans[1].EndTime)
+	}
+
+	// 测试用例3：读取完整的扫描报告
+	loaded, err := db.LoadScan(scans[0].ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !loaded.Info.StartTime.Equal(report.Info.StartTime) || loaded.Info.Options["ThreadCount"] != "25" || loaded.Info.Method != "GET" {
+		t.Errorf("Unexpected info: %+v", loaded.Info)
+	}
+	if len(loaded.Results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(loaded.Results))
+	}
+	got, expected := loaded.Results[1], report.Results[1]
+	if got.URL != expected.URL || got.Size != expected.Size || got.Depth != expected.Depth || got.Source != expected.Source || got.Title != expected.Title || got.Target != expected.Target {
+		t.Errorf("Expected %+v, got %+v", expected, got)
+	}
+
+	// 测试用例4：不存在的扫描
+	if _, err := db.LoadScan(100); err == nil {
+		t.Error("Expected error for missing scan")
+	}
+}
+
+func TestDatabasePath(t *testing.T) {
+	// 测试用例1：路径中的特殊字符按原样作为文件名
+	for _, name := range []string{"scan?mode=ro.db", "scan#1.db", "scan%20.db", "scan:1.db"} {
+		path := filepath.Join(t.TempDir(), name)
+		db, err := OpenDatabase(path)
+		if err != nil {
+			t.Fatalf("Expected no error for %q, got %v", name, err)
+		}
+		if _, err := db.StartScan(newTestReport().Info); err != nil {
+			t.Errorf("Expected writable database for %q, got %v", name, err)
+		}
+		db.Close()
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("Expected database file %q, got %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package report
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"HiDir/internal/utils"
+)
+
+// 结果的变化类型
+const (
+	ChangeNew     = "new"     // 新出现的路径
+	ChangeRemoved = "removed" // 消失的路径
+	ChangeChanged = "changed" // 状态码或大小发生变化
+)
+
+// Change 结果相对于旧扫描的变化
+type Change struct {
+	Type      string `json:"type" xml:"type,attr"`
+	OldStatus int    `json:"old_status,omitempty" xml:"old_status,attr,omitempty"`
+	OldSize   int64  `json:"old_size,omitempty" xml:"old_size,attr,omitempty"`
+}
+
+// String 返回变化的描述
+func (c *Change) String() string {
+	if c.Type != ChangeChanged {
+		return c.Type
+	}
+	return fmt.Sprintf("changed from %d %s", c.OldStatus, utils.HumanSize(c.OldSize))
+}
+
+// Diff 比较两次扫描，返回只包含变化结果的报告
+//
+// 结果按URL匹配，按目标和URL排序；消失的路径保留旧扫描中的结果。
+func Diff(before, after *Report, baseline string) *Report {
+	info := *after.Info
+	info.Baseline = baseline
+
+	previous := make(map[string]*Result, len(before.Results))
+	for _, result := range before.Results {
+		previous[result.URL] = result
+	}
+
+	results := make([]*Result, 0)
+	current := make(map[string]bool, len(after.Results))
+	for _, result := range after.Results {
+		current[result.URL] = true
+
+		old, ok := previous[result.URL]
+		switch {
+		case !ok:
+			results = append(results, withChange(result, &Change{Type: ChangeNew}))
+		case old.Status != result.Status || old.Size != result.Size:
+			results = append(results, withChange(result, &Change{Type: ChangeChanged, OldStatus: old.Status, OldSize: old.Size}))
+		}
+	}
+	for _, result := range before.Results {
+		if !current[result.URL] {
+			results = append(results, withChange(result, &Change{Type: ChangeRemoved}))
+		}
+	}
+
+	// 按目标分组，目标顺序与新扫描一致
+	order := make(map[string]int)
+	for _, target := range append(append([]string{}, after.Info.Targets...), before.Info.Targets...) {
+		if _, ok := order[target]; !ok {
+			order[target] = len(order)
+		}
+	}
+	rank := func(target string) int {
+		if index, ok := order[target]; ok {
+			return index
+		}
+		return len(order)
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if ra, rb := rank(a.Target), rank(b.Target); ra != rb {
+			return ra < rb
+		}
+		if a.Target != b.Target {
+			return a.Target < b.Target
+		}
+		return a.URL < b.URL
+	})
+
+	return &Report{Info: &info, Results: results}
+}
+
+// withChange 复制结果并设置变化
+func withChange(result *Result, change *Change) *Result {
+	copied := *result
+	copied.Change = change
+	return &copied
+}
+
+// LoadFile 读取json或jsonl格式的报告，扫描中断的json报告会自动补全
+func LoadFile(path string) (*Report, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch DetectFormat(path) {
+	case "json":
+		report := &Report{}
+		if err := json.Unmarshal(data, report); err != nil {
+			// 中断的报告缺少结尾
+			if repairErr := json.Unmarshal(append(data, "]}"...), report); repairErr != nil {
+				return nil, fmt.Errorf("invalid json report %s: %w", path, err)
+			}
+		}
+		if report.Info == nil {
+			report.Info = &Info{}
+		}
+		return report, nil
+	case "jsonl":
+		return parseJSONLines(path, data)
+	default:
+		return nil, fmt.Errorf("cannot read %s: only json and jsonl reports can be loaded", path)
+	}
+}
+
+// parseJSONLines 解析JSON Lines报告
+func parseJSONLines(path string, data []byte) (*Report, error) {
+	report := &Report{Info: &Info{}, Results: make([]*Result, 0)}
+
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		var record jsonLinesRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("invalid jsonl report %s at line %d: %w", path, line, err)
+		}
+		switch {
+		case record.Result != nil:
+			report.Results = append(report.Results, record.Result)
+		case record.Info != nil:
+			report.Info = record.Info
+		}
+	}
+	return report, scanner.Err()
+}
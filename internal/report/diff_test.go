@@ -0,0 +1,113 @@
+package report
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDiff(t *testing.T) {
+	before := newTestReport()
+	after := newTestReport()
+	after.Info.StartTime = before.Info.StartTime.Add(7 * 24 * time.Hour)
+	after.Results = []*Result{
+		// admin 状态码变化，login.php 消失，backup.zip 新出现
+		{Target: "http://example.com", URL: "http://example.com/admin", Status: 403, Size: 120},
+		{Target: "http://example.com", URL: "http://example.com/backup.zip", Status: 200, Size: 4096},
+		{Target: "http://other.com", URL: "http://other.com/", Status: 200, Size: 10},
+	}
+	after.Info.Targets = []string{"http://other.com", "http://example.com"}
+
+	diff := Diff(before, after, "old.json")
+
+	// 测试用例1：变化类型和排序
+	var got []string
+	for _, result := range diff.Results {
+		got = append(got, result.Change.Type+" "+result.URL)
+	}
+	expected := []string{
+		"new http://other.com/",
+		"changed http://example.com/admin",
+		"removed http://example.com/admin/login.php",
+		"new http://example.com/backup.zip",
+	}
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(got, "\n"))
+	}
+
+	// 测试用例2：记录旧的状态码和大小，不修改原报告
+	if change := diff.Results[1].Change; change.OldStatus != 301 || change.OldSize != 0 {
+		t.Errorf("Unexpected change: %+v", change)
+	}
+	if after.Results[0].Change != nil || after.Info.Baseline != "" {
+		t.Error("Expected Diff not to modify the input reports")
+	}
+	if diff.Info.Baseline != "old.json" || !diff.Info.StartTime.Equal(after.Info.StartTime) {
+		t.Errorf("Unexpected info: %+v", diff.Info)
+	}
+
+	// 测试用例3：差异报告的CSV多一列变化
+	var buf bytes.Buffer
+	if err := Write(&CSVWriter{}, &buf, diff); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), "Change,URL") || !strings.Contains(buf.String(), "changed from 301 0B,http://example.com/admin") {
+		t.Errorf("Unexpected CSV:\n%s", buf.String())
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	dir := t.TempDir()
+	report := newTestReport()
+
+	for _, format := range []string{"json", "jsonl"} {
+		// 测试用例1：读取完整的报告
+		t.Run(format, func(t *testing.T) {
+			path := filepath.Join(dir, "report."+format)
+			file, err := CreateFile(path, format, report.Info)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			for _, result := range report.Results {
+				file.Add(result)
+			}
+			file.Close(report.Info.EndTime)
+
+			loaded, err := LoadFile(path)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if len(loaded.Results) != 2 || loaded.Info.Method != "GET" || loaded.Results[1].Title != "Login | Admin" {
+				t.Errorf("Unexpected report: %+v", loaded)
+			}
+		})
+	}
+
+	// 测试用例2：中断的JSON报告
+	t.Run("InterruptedJSON", func(t *testing.T) {
+		path := filepath.Join(dir, "interrupted.json")
+		file, _ := CreateFile(path, "json", report.Info)
+		file.Add(report.Results[0])
+
+		loaded, err := LoadFile(path)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(loaded.Results) != 1 {
+			t.Errorf("Expected 1 result, got %d", len(loaded.Results))
+		}
+		file.Close(time.Now())
+	})
+
+	// 测试用例3：不支持的格式
+	t.Run("Unsupported", func(t *testing.T) {
+		path := filepath.Join(dir, "report.csv")
+		os.WriteFile(path, []byte("URL\n"), 0644)
+		if _, err := LoadFile(path); err == nil {
+			t.Error("Expected error for csv report")
+		}
+	})
+}
@@ -0,0 +1,158 @@
+package report
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// 报告文件同步到磁盘的频率，满足任一条件即调用fsync
+var (
+	SyncEvery    = 20              // 每写入多少个结果同步一次
+	SyncInterval = 5 * time.Second // 有未同步的结果时，距离上次同步的最长时间
+)
+
+// File 增量写入的报告文件
+//
+// 每个结果写入后立即刷新到操作系统，进程被终止也不会丢失；
+// 并定期调用fsync，即使之后没有新的结果，未同步的结果最多等待SyncInterval，减少系统崩溃时的损失。
+type File struct {
+	path     string
+	file     *os.File
+	buffer   *bufio.Writer
+	writer   Writer
+	info     *Info
+	pending  int
+	lastSync time.Time
+	syncErr  error         // 后台同步的错误，在Close时返回
+	stop     chan struct{} // 关闭时停止后台同步
+	done     chan struct{} // 后台同步结束时关闭
+	mu       sync.Mutex
+}
+
+// CreateFile 创建报告文件并写入开头
+func CreateFile(path, format string, info *Info) (*File, error) {
+	writer, err := NewWriter(format)
+	if err != nil {
+		return nil, err
+	}
+
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create report directory: %w", err)
+		}
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create report file: %w", err)
+	}
+
+	f := &File{
+		path:     path,
+		file:     file,
+		buffer:   bufio.NewWriter(file),
+		writer:   writer,
+		info:     info,
+		lastSync: time.Now(),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	if err := writer.Begin(f.buffer, info); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to write report %s: %w", path, err)
+	}
+	if err := f.sync(); err != nil {
+		file.Close()
+		return nil, err
+	}
+	go f.syncLoop()
+
+	return f, nil
+}
+
+// Path 获取报告文件路径
+func (f *File) Path() string {
+	return f.path
+}
+
+// Add 追加一个结果
+func (f *File) Add(result *Result) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.writer.WriteResult(f.buffer, result); err != nil {
+		return fmt.Errorf("failed to write report %s: %w", f.path, err)
+	}
+	if err := f.buffer.Flush(); err != nil {
+		return fmt.Errorf("failed to write report %s: %w", f.path, err)
+	}
+
+	f.pending++
+	if f.pending >= SyncEvery || time.Since(f.lastSync) >= SyncInterval {
+		return f.sync()
+	}
+	return nil
+}
+
+// Close 写入报告结尾并关闭文件
+func (f *File) Close(endTime time.Time) error {
+	close(f.stop)
+	<-f.done
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.info.EndTime = endTime
+	err := f.syncErr
+	if err == nil {
+		err = f.writer.End(f.buffer, f.info)
+	}
+	if err == nil {
+		err = f.sync()
+	}
+	if closeErr := f.file.Close(); err == nil && closeErr != nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write report %s: %w", f.path, err)
+	}
+	return nil
+}
+
+// syncLoop 定期同步未同步的结果，直到Close
+func (f *File) syncLoop() {
+	defer close(f.done)
+
+	ticker := time.NewTicker(SyncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-f.stop:
+			return
+		case <-ticker.C:
+			f.mu.Lock()
+			if f.pending > 0 && f.syncErr == nil {
+				f.syncErr = f.sync()
+			}
+			f.mu.Unlock()
+		}
+	}
+}
+
+// sync 刷新缓冲区并同步到磁盘
+func (f *File) sync() error {
+	if err := f.buffer.Flush(); err != nil {
+		return err
+	}
+	if err := f.file.Sync(); err != nil {
+		return err
+	}
+	f.pending = 0
+	f.lastSync = time.Now()
+	return nil
+}
@@ -0,0 +1,146 @@
+package report
+
+import (
+	"bufio"
+	"encoding/json"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFile(t *testing.T) {
+	dir := t.TempDir()
+	report := newTestReport()
+
+	// 测试用例1：结果在写入后立即出现在文件中
+	t.Run("Incremental", func(t *testing.T) {
+		path := filepath.Join(dir, "sub", "report.csv")
+		file, err := CreateFile(path, "csv", report.Info)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		for i, result := range report.Results {
+			if err := file.Add(result); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			data, _ := os.ReadFile(path)
+			if lines := strings.Count(string(data), "\n"); lines != i+2 {
+				t.Errorf("Expected %d lines after %d results, got %d", i+2, i+1, lines)
+			}
+		}
+		if err := file.Close(time.Now()); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	})
+
+	// 测试用例2：中断的JSON报告补上结尾即可解析
+	t.Run("InterruptedJSON", func(t *testing.T) {
+		path := filepath.Join(dir, "report.json")
+		file, err := CreateFile(path, "json", report.Info)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		for _, result := range report.Results {
+			file.Add(result)
+		}
+
+		data, _ := os.ReadFile(path)
+		var decoded Report
+		if err := json.Unmarshal(append(data, "]}"...), &decoded); err != nil {
+			t.Fatalf("Expected repairable JSON, got %v\n%s", err, data)
+		}
+		if len(decoded.Results) != 2 {
+			t.Errorf("Expected 2 results, got %d", len(decoded.Results))
+		}
+		file.Close(time.Now())
+	})
+
+	// 测试用例3：中断的XML报告补上结尾即可解析
+	t.Run("InterruptedXML", func(t *testing.T) {
+		path := filepath.Join(dir, "report.xml")
+		file, err := CreateFile(path, "xml", report.Info)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		for _, result := range report.Results {
+			file.Add(result)
+		}
+
+		data, _ := os.ReadFile(path)
+		var decoded xmlReport
+		if err := xml.Unmarshal(append(data, "</results></hidirscan>"...), &decoded); err != nil {
+			t.Fatalf("Expected repairable XML, got %v\n%s", err, data)
+		}
+		if len(decoded.Results) != 2 {
+			t.Errorf("Expected 2 results, got %d", len(decoded.Results))
+		}
+		file.Close(time.Now())
+	})
+
+	// 测试用例4：JSON Lines每行都是完整的JSON对象
+	t.Run("JSONLines", func(t *testing.T) {
+		path := filepath.Join(dir, "report.jsonl")
+		file, err := CreateFile(path, "jsonl", report.Info)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		for _, result := range report.Results {
+			file.Add(result)
+		}
+		file.Close(time.Now())
+
+		f, _ := os.Open(path)
+		defer f.Close()
+		var types []string
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			var record jsonLinesRecord
+			if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+				t.Fatalf("Expected valid JSON line, got %v", err)
+			}
+			types = append(types, record.Type)
+		}
+		if strings.Join(types, ",") != "start,result,result,end" {
+			t.Errorf("Unexpected record types: %v", types)
+		}
+	})
+
+	// 测试用例5：不支持的格式
+	t.Run("Unsupported", func(t *testing.T) {
+		if _, err := CreateFile(filepath.Join(dir, "report.yaml"), "yaml", report.Info); err == nil {
+			t.Error("Expected error for unsupported format")
+		}
+	})
+}
+
+func TestFilePeriodicSync(t *testing.T) {
+	interval, every := SyncInterval, SyncEvery
+	SyncInterval, SyncEvery = 20*time.Millisecond, 1000
+	defer func() { SyncInterval, SyncEvery = interval, every }()
+
+	report := newTestReport()
+	file, err := CreateFile(filepath.Join(t.TempDir(), "report.csv"), "csv", report.Info)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer file.Close(time.Now())
+
+	// 之后没有新的结果，已写入的结果也会在间隔后同步
+	file.Add(report.Results[0])
+	deadline := time.Now().Add(time.Second)
+	for {
+		file.mu.Lock()
+		pending := file.pending
+		file.mu.Unlock()
+		if pending == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the pending result to be synced without further writes")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
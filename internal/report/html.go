@@ -0,0 +1,73 @@
+package report
+
+import (
+	"html/template"
+	"io"
+	"time"
+
+	"HiDir/internal/utils"
+)
+
+// HTML报告模板，分为开头、单个结果和结尾三部分
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"humanSize": utils.HumanSize,
+	"datetime":  formatTime,
+	"change":    changeText,
+	"statusClass": func(status int) int {
+		return status / 100
+	},
+}).Parse(`{{define "begin"}}<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>HiDir Report</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; width: 100%; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; font-size: 14px; }
+th { background: #f0f0f0; }
+.s2 { color: #2e7d32; } .s3 { color: #1565c0; } .s4 { color: #ef6c00; } .s5 { color: #c62828; }
+</style>
+</head>
+<body>
+<h1>HiDir Report</h1>
+<ul>
+{{if .Baseline}}<li>Baseline: {{.Baseline}}</li>
+{{end}}<li>Command: <code>{{.Command}}</code></li>
+<li>Method: {{.Method}}</li>
+<li>Targets: {{range $i, $t := .Targets}}{{if $i}}, {{end}}{{$t}}{{end}}</li>
+<li>Wordlists: {{range $i, $w := .Wordlists}}{{if $i}}, {{end}}{{$w}}{{end}}</li>
+<li>Start time: {{datetime .StartTime}}</li>
+{{range $k, $v := .Options}}<li>{{$k}}: <code>{{$v}}</code></li>
+{{end}}</ul>
+<table>
+<tr>{{if .Baseline}}<th>Change</th>{{end}}<th>URL</th><th>Status</th><th>Size</th><th>Content Type</th><th>Redirection</th><th>Depth</th><th>Source</th><th>Words</th><th>Lines</th><th>Title</th><th>Body Hash</th></tr>
+{{end}}{{define "result"}}<tr>{{if .Change}}<td>{{change .Change}}</td>{{end}}<td><a href="{{.URL}}">{{.URL}}</a></td><td class="s{{statusClass .Status}}">{{.Status}}</td><td>{{humanSize .Size}}</td><td>{{.ContentType}}</td><td>{{.Redirect}}</td><td>{{.Depth}}</td><td>{{if .Source}}<a href="{{.Source}}">{{.Source}}</a>{{end}}</td><td>{{.Words}}</td><td>{{.Lines}}</td><td>{{.Title}}</td><td><code>{{.BodyHash}}</code></td></tr>
+{{end}}{{define "end"}}</table>
+<p>End time: {{datetime .EndTime}}</p>
+</body>
+</html>
+{{end}}`))
+
+// HTMLWriter HTML格式，浏览器可以直接显示中断的报告
+type HTMLWriter struct{}
+
+// Begin 写入报告开头
+func (hw *HTMLWriter) Begin(w io.Writer, info *Info) error {
+	return htmlTemplate.ExecuteTemplate(w, "begin", info)
+}
+
+// WriteResult 追加一个结果
+func (hw *HTMLWriter) WriteResult(w io.Writer, result *Result) error {
+	return htmlTemplate.ExecuteTemplate(w, "result", result)
+}
+
+// End 写入报告结尾
+func (hw *HTMLWriter) End(w io.Writer, info *Info) error {
+	return htmlTemplate.ExecuteTemplate(w, "end", info)
+}
+
+// formatTime 格式化报告中的时间
+func formatTime(t time.Time) string {
+	return t.Format("2006-01-02 15:04:05")
+}
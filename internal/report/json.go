@@ -0,0 +1,72 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// JSONWriter JSON格式
+//
+// 结果数组写在前面，扫描信息在结尾写入，中断的报告补上 "]}" 即可解析。
+type JSONWriter struct {
+	count int
+}
+
+// Begin 写入报告开头
+func (jw *JSONWriter) Begin(w io.Writer, info *Info) error {
+	jw.count = 0
+	_, err := io.WriteString(w, "{\n  \"results\": [")
+	return err
+}
+
+// WriteResult 追加一个结果
+func (jw *JSONWriter) WriteResult(w io.Writer, result *Result) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+
+	separator := "\n    "
+	if jw.count > 0 {
+		separator = ",\n    "
+	}
+	jw.count++
+	_, err = fmt.Fprintf(w, "%s%s", separator, data)
+	return err
+}
+
+// End 写入报告结尾
+func (jw *JSONWriter) End(w io.Writer, info *Info) error {
+	data, err := json.MarshalIndent(info, "  ", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "\n  ],\n  \"info\": %s\n}\n", data)
+	return err
+}
+
+// jsonLinesRecord JSON Lines报告中的一行
+type jsonLinesRecord struct {
+	Type   string  `json:"type"` // start、result 或 end
+	Info   *Info   `json:"info,omitempty"`
+	Result *Result `json:"result,omitempty"`
+}
+
+// JSONLinesWriter JSON Lines格式，每行一个独立的JSON对象
+type JSONLinesWriter struct{}
+
+// Begin 写入报告开头
+func (jw *JSONLinesWriter) Begin(w io.Writer, info *Info) error {
+	return json.NewEncoder(w).Encode(jsonLinesRecord{Type: "start", Info: info})
+}
+
+// WriteResult 追加一个结果
+func (jw *JSONLinesWriter) WriteResult(w io.Writer, result *Result) error {
+	return json.NewEncoder(w).Encode(jsonLinesRecord{Type: "result", Result: result})
+}
+
+// End 写入报告结尾
+func (jw *JSONLinesWriter) End(w io.Writer, info *Info) error {
+	return json.NewEncoder(w).Encode(jsonLinesRecord{Type: "end", Info: info})
+}
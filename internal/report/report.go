@@ -0,0 +1,283 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+
+	"HiDir/internal/common"
+	"HiDir/internal/utils"
+)
+
+// Info 扫描的元数据
+type Info struct {
+	Version   string            `json:"version" xml:"version,attr"`
+	Command   string            `json:"command" xml:"command,attr"`
+	Targets   []string          `json:"targets" xml:"target"`
+	Wordlists []string          `json:"wordlists" xml:"wordlist"`
+	Method    string            `json:"method" xml:"method,attr"`
+	StartTime time.Time         `json:"start_time" xml:"start_time,attr"`
+	EndTime   time.Time         `json:"end_time" xml:"-"` // XML报告在结尾的finished元素中写入
+	Options   map[string]string `json:"options" xml:"-"`
+	Baseline  string            `json:"baseline,omitempty" xml:"baseline,attr,omitempty"` // 差异报告对比的旧扫描
+}
+
+// Result 单个扫描结果
+type Result struct {
+	Target      string    `json:"target" xml:"target,attr"`
+	URL         string    `json:"url" xml:"url,attr"`
+	Path        string    `json:"path" xml:"path,attr"`
+	Status      int       `json:"status" xml:"status,attr"`
+	Size        int64     `json:"size" xml:"size,attr"`
+	ContentType string    `json:"content_type" xml:"content_type,attr"`
+	Redirect    string    `json:"redirect" xml:"redirect,attr"`
+	Depth       int       `json:"depth" xml:"depth,attr"`
+	Source      string    `json:"source,omitempty" xml:"source,attr,omitempty"` // 发现当前目录的结果URL，初始目录为空
+	Words       int       `json:"words" xml:"words,attr"`
+	Lines       int       `json:"lines" xml:"lines,attr"`
+	Title       string    `json:"title" xml:"title,attr"`
+	BodyHash    string    `json:"body_hash" xml:"body_hash,attr"`
+	Time        time.Time `json:"time" xml:"time,attr"`
+	Change      *Change   `json:"change,omitempty" xml:"change,omitempty"` // 只在差异报告中出现
+}
+
+// Report 完整的扫描报告
+type Report struct {
+	Info    *Info     `json:"info"`
+	Results []*Result `json:"results"`
+}
+
+// Writer 报告写入器，每种格式一个实现
+//
+// 报告分三部分增量写入：Begin写入开头，每个结果匹配后调用WriteResult追加，
+// 扫描结束时End写入结尾。只写了开头和部分结果的报告也是有效的或者易于修复的。
+// Writer可能保存写入状态，每份报告需要使用新的实例。
+type Writer interface {
+	// Begin 写入报告开头，此时info中还没有结束时间
+	Begin(w io.Writer, info *Info) error
+	// WriteResult 追加一个结果
+	WriteResult(w io.Writer, result *Result) error
+	// End 写入报告结尾
+	End(w io.Writer, info *Info) error
+}
+
+// NewWriter 根据格式名称创建Writer，sqlite格式由Database写入，不支持Writer
+func NewWriter(format string) (Writer, error) {
+	switch strings.ToLower(format) {
+	case "simple":
+		return &SimpleWriter{}, nil
+	case "plain":
+		return &PlainWriter{}, nil
+	case "json":
+		return &JSONWriter{}, nil
+	case "jsonl":
+		return &JSONLinesWriter{}, nil
+	case "xml":
+		return &XMLWriter{}, nil
+	case "md":
+		return &MarkdownWriter{}, nil
+	case "csv":
+		return &CSVWriter{}, nil
+	case "html":
+		return &HTMLWriter{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported report format %q (supported: %s)", format, strings.Join(common.OUTPUT_FORMATS, ", "))
+	}
+}
+
+// Output 扫描过程中结果的写入目标，报告文件或数据库
+type Output interface {
+	// Path 获取输出路径
+	Path() string
+	// Add 追加一个结果
+	Add(result *Result) error
+	// Close 写入结束信息并关闭
+	Close(endTime time.Time) error
+}
+
+// Open 根据格式创建报告文件或者在数据库中开始一次新的扫描
+func Open(path, format string, info *Info) (Output, error) {
+	if strings.ToLower(format) != "sqlite" {
+		return CreateFile(path, format, info)
+	}
+
+	db, err := OpenDatabase(path)
+	if err != nil {
+		return nil, err
+	}
+	scanID, err := db.StartScan(info)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to write database %s: %w", path, err)
+	}
+	return &databaseOutput{path: path, db: db, scanID: scanID}, nil
+}
+
+// IsSupported 检查是否支持该输出格式
+func IsSupported(format string) bool {
+	for _, supported := range common.OUTPUT_FORMATS {
+		if strings.EqualFold(format, supported) {
+			return true
+		}
+	}
+	return false
+}
+
+// Write 使用writer一次性写入完整的报告
+func Write(writer Writer, w io.Writer, report *Report) error {
+	if err := writer.Begin(w, report.Info); err != nil {
+		return err
+	}
+	for _, result := range report.Results {
+		if err := writer.WriteResult(w, result); err != nil {
+			return err
+		}
+	}
+	return writer.End(w, report.Info)
+}
+
+// DetectFormat 根据文件扩展名推断报告格式，无法识别时返回plain
+func DetectFormat(path string) string {
+	extension := strings.ToLower(strings.TrimPrefix(filepath.Ext(path), "."))
+	switch extension {
+	case "markdown":
+		return "md"
+	case "htm":
+		return "html"
+	case "db", "sqlite3":
+		return "sqlite"
+	case "txt", "":
+		return "plain"
+	}
+	for _, format := range common.OUTPUT_FORMATS {
+		if format == extension {
+			return format
+		}
+	}
+	return "plain"
+}
+
+// ExpandPath 替换输出路径中的占位符
+//
+//	{host}    目标主机名（含端口）
+//	{scheme}  目标协议
+//	{date}    开始日期，如 2006-01-02
+//	{time}    开始时间，如 15-04-05
+//	{format}  报告格式
+func ExpandPath(pattern, target, format string, start time.Time) string {
+	host, scheme := target, ""
+	if parsed, err := url.Parse(target); err == nil && parsed.Host != "" {
+		host, scheme = parsed.Host, parsed.Scheme
+	}
+
+	replacer := strings.NewReplacer(
+		"{host}", utils.GetValidFilename(host),
+		"{scheme}", scheme,
+		"{date}", start.Format("2006-01-02"),
+		"{time}", start.Format("15-04-05"),
+		"{format}", format,
+	)
+	return replacer.Replace(pattern)
+}
+
+// HasTargetPlaceholder 检查输出路径是否需要按目标分别生成报告
+func HasTargetPlaceholder(pattern string) bool {
+	return strings.Contains(pattern, "{host}") || strings.Contains(pattern, "{scheme}")
+}
+
+// redacted 替换敏感选项值的文本
+const redacted = "[REDACTED]"
+
+// sensitiveOptions 值中可能包含凭据或会话令牌的选项，不写入报告
+var sensitiveOptions = map[string]bool{
+	"Auth":      true,
+	"ProxyAuth": true,
+	"Cookie":    true,
+	"Headers":   true,
+	"Data":      true,
+}
+
+// sensitiveFlags 对应 sensitiveOptions 的命令行参数
+var sensitiveFlags = []string{"--auth", "--proxy-auth", "--cookie", "--header", "-H", "--data", "-d"}
+
+// OptionsMap 将选项结构体中的非零值字段转换为字符串映射，凭据类选项只记录为已设置
+func OptionsMap(options interface{}) map[string]string {
+	result := make(map[string]string)
+
+	value := reflect.Indirect(reflect.ValueOf(options))
+	if value.Kind() != reflect.Struct {
+		return result
+	}
+
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Field(i)
+		name := value.Type().Field(i).Name
+		if !value.Type().Field(i).IsExported() || field.IsZero() {
+			continue
+		}
+		if sensitiveOptions[name] {
+			result[name] = redacted
+			continue
+		}
+		if field.Kind() == reflect.Slice {
+			parts := make([]string, field.Len())
+			for j := range parts {
+				parts[j] = redactURL(fmt.Sprint(field.Index(j).Interface()))
+			}
+			result[name] = strings.Join(parts, ",")
+			continue
+		}
+		result[name] = redactURL(fmt.Sprint(field.Interface()))
+	}
+
+	return result
+}
+
+// RedactCommand 拼接命令行参数，去除凭据类参数的值以及URL中的密码
+func RedactCommand(args []string) string {
+	parts := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		flag, separator, ok := sensitiveFlag(args[i])
+		switch {
+		case !ok:
+			parts = append(parts, redactURL(args[i]))
+		case separator == "" && args[i] == flag && i+1 < len(args):
+			// 值为下一个参数
+			parts = append(parts, flag, redacted)
+			i++
+		default:
+			parts = append(parts, flag+separator+redacted)
+		}
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// sensitiveFlag 检查参数是否为凭据类参数，返回参数名及其与值之间的分隔符
+func sensitiveFlag(arg string) (flag, separator string, ok bool) {
+	for _, flag := range sensitiveFlags {
+		switch {
+		case arg == flag:
+			return flag, "", true
+		case strings.HasPrefix(arg, flag+"="):
+			return flag, "=", true
+		case len(flag) == 2 && strings.HasPrefix(arg, flag):
+			// 短参数的值可以紧跟参数，如 -HCookie:x
+			return flag, "", true
+		}
+	}
+	return "", "", false
+}
+
+// redactURL 隐藏URL中的密码，其他值原样返回
+func redactURL(value string) string {
+	parsed, err := url.Parse(value)
+	if err != nil || parsed.User == nil {
+		return value
+	}
+	return parsed.Redacted()
+}
@@ -0,0 +1,251 @@
+package report
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"HiDir/internal/common"
+	"HiDir/internal/parse"
+)
+
+// newTestReport 创建测试用的报告
+func newTestReport() *Report {
+	start := time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC)
+	return &Report{
+		Info: &Info{
+			Version:   "1.0.0",
+			Command:   "hidir -u http://example.com",
+			Targets:   []string{"http://example.com"},
+			Wordlists: []string{"dict/common.txt"},
+			Method:    "GET",
+			StartTime: start,
+			EndTime:   start.Add(time.Minute),
+			Options:   map[string]string{"ThreadCount": "25"},
+		},
+		Results: []*Result{
+			{Target: "http://example.com", URL: "http://example.com/admin", Path: "admin", Status: 301, Size: 0, ContentType: "text/html", Redirect: "http://example.com/admin/", Depth: 0},
+			{Target: "http://example.com", URL: "http://example.com/admin/login.php", Path: "login.php", Status: 200, Size: 2048, ContentType: "text/html", Depth: 1, Source: "http://example.com/admin", Title: "Login | Admin", BodyHash: testBodyHash},
+		},
+	}
+}
+
+// testBodyHash 测试结果的响应体哈希
+const testBodyHash = "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+
+func TestWriters(t *testing.T) {
+	// 测试用例1：每种格式都有对应的Writer，且输出包含所有结果
+	for _, format := range common.OUTPUT_FORMATS {
+		if format == "sqlite" {
+			continue
+		}
+		t.Run(format, func(t *testing.T) {
+			writer, err := NewWriter(format)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			var buf bytes.Buffer
+			if err := Write(writer, &buf, newTestReport()); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			for _, url := range []string{"http://example.com/admin", "http://example.com/admin/login.php"} {
+				if !strings.Contains(buf.String(), url) {
+					t.Errorf("Expected %s output to contain %s", format, url)
+				}
+			}
+			// 只输出URL的格式不包含响应体哈希
+			if format != "simple" && !strings.Contains(buf.String(), testBodyHash) {
+				t.Errorf("Expected %s output to contain the body hash", format)
+			}
+		})
+	}
+
+	// 测试用例2：不支持的格式
+	t.Run("Unsupported", func(t *testing.T) {
+		if _, err := NewWriter("yaml"); err == nil {
+			t.Error("Expected error for unsupported format")
+		}
+	})
+
+	// 测试用例3：JSON可以解析回报告
+	t.Run("JSONRoundTrip", func(t *testing.T) {
+		var buf bytes.Buffer
+		Write(&JSONWriter{}, &buf, newTestReport())
+		var decoded Report
+		if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+			t.Fatalf("Expected valid JSON, got %v", err)
+		}
+		if len(decoded.Results) != 2 || decoded.Results[1].Depth != 1 || decoded.Results[0].Redirect != "http://example.com/admin/" {
+			t.Errorf("Unexpected results: %+v", decoded.Results)
+		}
+		if decoded.Info.Method != "GET" || decoded.Info.Options["ThreadCount"] != "25" {
+			t.Errorf("Unexpected info: %+v", decoded.Info)
+		}
+	})
+
+	// 测试用例4：XML格式正确
+	t.Run("XMLWellFormed", func(t *testing.T) {
+		var buf bytes.Buffer
+		Write(&XMLWriter{}, &buf, newTestReport())
+		var decoded xmlReport
+		if err := xml.Unmarshal(buf.Bytes(), &decoded); err != nil {
+			t.Fatalf("Expected valid XML, got %v", err)
+		}
+		if len(decoded.Results) != 2 || decoded.Results[1].Status != 200 {
+			t.Errorf("Unexpected results: %+v", decoded.Results)
+		}
+		if decoded.Info.Method != "GET" || decoded.Finished.EndTime.IsZero() {
+			t.Errorf("Unexpected info: %+v %+v", decoded.Info, decoded.Finished)
+		}
+	})
+
+	// 测试用例5：CSV包含表头和每个结果一行
+	t.Run("CSVRows", func(t *testing.T) {
+		var buf bytes.Buffer
+		Write(&CSVWriter{}, &buf, newTestReport())
+		records, err := csv.NewReader(&buf).ReadAll()
+		if err != nil {
+			t.Fatalf("Expected valid CSV, got %v", err)
+		}
+		if len(records) != 3 || records[2][1] != "200" {
+			t.Errorf("Unexpected records: %v", records)
+		}
+	})
+
+	// 测试用例6：表格和文本格式记录发现该结果的目录
+	t.Run("Source", func(t *testing.T) {
+		expected := map[string]string{
+			"plain": "login.php  <- FOUND IN: http://example.com/admin",
+			"md":    "| 1 | http://example.com/admin |",
+			"csv":   "1,http://example.com/admin,",
+			"html":  `<td>1</td><td><a href="http://example.com/admin">http://example.com/admin</a></td>`,
+		}
+		for format, text := range expected {
+			writer, _ := NewWriter(format)
+			var buf bytes.Buffer
+			Write(writer, &buf, newTestReport())
+			if !strings.Contains(buf.String(), text) {
+				t.Errorf("Expected %s output to contain %q, got:\n%s", format, text, buf.String())
+			}
+		}
+	})
+
+	// 测试用例7：Markdown转义表格分隔符
+	t.Run("MarkdownEscape", func(t *testing.T) {
+		var buf bytes.Buffer
+		Write(&MarkdownWriter{}, &buf, newTestReport())
+		if !strings.Contains(buf.String(), `Login \| Admin`) {
+			t.Errorf("Expected pipe in title to be escaped, got:\n%s", buf.String())
+		}
+	})
+}
+
+func TestDetectFormat(t *testing.T) {
+	tests := map[string]string{
+		"report.json":     "json",
+		"out/report.HTML": "html",
+		"report.htm":      "html",
+		"report.markdown": "md",
+		"scans/weekly.db": "sqlite",
+		"report.txt":      "plain",
+		"report":          "plain",
+		"report.unknown":  "plain",
+	}
+	for path, expected := range tests {
+		if format := DetectFormat(path); format != expected {
+			t.Errorf("DetectFormat(%q) = %q, expected %q", path, format, expected)
+		}
+	}
+}
+
+func TestExpandPath(t *testing.T) {
+	start := time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC)
+
+	// 测试用例1：替换所有占位符
+	path := ExpandPath("reports/{scheme}_{host}_{date}_{time}.{format}", "https://example.com:8443/app/", "json", start)
+	if strings.ContainsAny(path, "{}") || !strings.Contains(path, "https_example.com") || !strings.HasSuffix(path, "2024-05-01_10-30-00.json") {
+		t.Errorf("Unexpected path: %s", path)
+	}
+
+	// 测试用例2：只有目标占位符需要按目标拆分
+	if !HasTargetPlaceholder("reports/{host}.json") || HasTargetPlaceholder("reports/{date}.json") {
+		t.Error("Unexpected HasTargetPlaceholder result")
+	}
+}
+
+func TestOptionsMap(t *testing.T) {
+	options := struct {
+		Threads    int
+		Extensions []string
+		Empty      string
+		hidden     string
+	}{Threads: 25, Extensions: []string{"php", "html"}, hidden: "x"}
+
+	result := OptionsMap(&options)
+	if len(result) != 2 || result["Threads"] != "25" || result["Extensions"] != "php,html" {
+		t.Errorf("Unexpected options map: %v", result)
+	}
+}
+
+func TestRedactCredentials(t *testing.T) {
+	const secret = "admin:s3cr3t-p4ss"
+	options := &parse.Options{
+		ThreadCount: 25,
+		Auth:        secret,
+		ProxyAuth:   secret,
+		Cookie:      "session=" + secret,
+		Headers:     []string{"Authorization: Bearer " + secret},
+		Data:        "password=" + secret,
+		Proxies:     []string{"http://" + secret + "@127.0.0.1:8080"},
+	}
+	args := []string{"hidir", "-u", "http://example.com", "--auth", secret, "--cookie=session=" + secret,
+		"-HAuthorization:" + secret, "-d", "password=" + secret, "--proxy", "http://" + secret + "@127.0.0.1:8080"}
+
+	report := newTestReport()
+	report.Info.Options = OptionsMap(options)
+	report.Info.Command = RedactCommand(args)
+
+	// 测试用例1：选项中保留非凭据选项
+	if report.Info.Options["ThreadCount"] != "25" || report.Info.Options["Auth"] != redacted {
+		t.Errorf("Unexpected options map: %v", report.Info.Options)
+	}
+	if !strings.Contains(report.Info.Command, "-u http://example.com") {
+		t.Errorf("Unexpected command: %s", report.Info.Command)
+	}
+
+	// 测试用例2：任何格式的报告中都不包含凭据
+	for _, format := range common.OUTPUT_FORMATS {
+		if format == "sqlite" {
+			continue
+		}
+		writer, _ := NewWriter(format)
+		var buf bytes.Buffer
+		if err := Write(writer, &buf, report); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if strings.Contains(buf.String(), "s3cr3t") {
+			t.Errorf("Expected %s output not to contain credentials:\n%s", format, buf.String())
+		}
+	}
+
+	// 测试用例3：数据库中不包含凭据
+	path := filepath.Join(t.TempDir(), "results.db")
+	output, err := Open(path, "sqlite", report.Info)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	output.Close(report.Info.EndTime)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if bytes.Contains(data, []byte("s3cr3t")) {
+		t.Error("Expected database not to contain credentials")
+	}
+}
@@ -0,0 +1,162 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"HiDir/internal/utils"
+)
+
+// SimpleWriter 每行一个URL
+type SimpleWriter struct{}
+
+// Begin 写入报告开头
+func (sw *SimpleWriter) Begin(w io.Writer, info *Info) error {
+	return nil
+}
+
+// WriteResult 追加一个结果，差异报告中以 +、-、~ 标记新增、消失和变化的路径
+func (sw *SimpleWriter) WriteResult(w io.Writer, result *Result) error {
+	if result.Change != nil {
+		_, err := fmt.Fprintf(w, "%s %s\n", changeMarker(result.Change), result.URL)
+		return err
+	}
+	_, err := fmt.Fprintln(w, result.URL)
+	return err
+}
+
+// End 写入报告结尾
+func (sw *SimpleWriter) End(w io.Writer, info *Info) error {
+	return nil
+}
+
+// PlainWriter 纯文本格式，与控制台输出类似
+type PlainWriter struct{}
+
+// Begin 写入报告开头
+func (pw *PlainWriter) Begin(w io.Writer, info *Info) error {
+	if info.Baseline != "" {
+		fmt.Fprintf(w, "# Changes since %s\n", info.Baseline)
+	}
+	_, err := fmt.Fprintf(w, "# HiDir %s started %s as: %s\n\n", info.Version, formatTime(info.StartTime), info.Command)
+	return err
+}
+
+// WriteResult 追加一个结果
+func (pw *PlainWriter) WriteResult(w io.Writer, result *Result) error {
+	line := ""
+	if result.Change != nil {
+		line = changeMarker(result.Change) + " "
+	}
+	line += fmt.Sprintf("%d  %7s  %6dW  %5dL  %s", result.Status, utils.HumanSize(result.Size), result.Words, result.Lines, result.URL)
+	if result.Redirect != "" {
+		line += "  -> REDIRECTS TO: " + result.Redirect
+	}
+	if result.Source != "" {
+		line += "  <- FOUND IN: " + result.Source
+	}
+	if result.Title != "" {
+		line += "  [" + result.Title + "]"
+	}
+	if result.BodyHash != "" {
+		line += "  sha256:" + result.BodyHash
+	}
+	if result.Change != nil && result.Change.Type == ChangeChanged {
+		line += "  (" + result.Change.String() + ")"
+	}
+	_, err := fmt.Fprintln(w, line)
+	return err
+}
+
+// End 写入报告结尾
+func (pw *PlainWriter) End(w io.Writer, info *Info) error {
+	_, err := fmt.Fprintf(w, "\n# Finished %s\n", formatTime(info.EndTime))
+	return err
+}
+
+// MarkdownWriter Markdown格式
+type MarkdownWriter struct {
+	diff bool // 是否为差异报告，差异报告多一列变化
+}
+
+// Begin 写入报告开头
+func (mw *MarkdownWriter) Begin(w io.Writer, info *Info) error {
+	mw.diff = info.Baseline != ""
+
+	fmt.Fprintf(w, "### Info\n\n")
+	if mw.diff {
+		fmt.Fprintf(w, "- Baseline: %s\n", info.Baseline)
+	}
+	fmt.Fprintf(w, "- Command: `%s`\n", info.Command)
+	fmt.Fprintf(w, "- Method: %s\n", info.Method)
+	fmt.Fprintf(w, "- Targets: %s\n", strings.Join(info.Targets, ", "))
+	fmt.Fprintf(w, "- Wordlists: %s\n", strings.Join(info.Wordlists, ", "))
+	fmt.Fprintf(w, "- Start time: %s\n", formatTime(info.StartTime))
+	for _, key := range sortedKeys(info.Options) {
+		fmt.Fprintf(w, "- %s: `%s`\n", key, info.Options[key])
+	}
+
+	fmt.Fprintf(w, "\n### Results\n\n")
+	if mw.diff {
+		fmt.Fprintln(w, "| Change | URL | Status | Size | Content Type | Redirection | Depth | Source | Words | Lines | Title | Body Hash |")
+		_, err := fmt.Fprintln(w, "|--------|-----|--------|------|--------------|-------------|-------|--------|-------|-------|-------|-----------|")
+		return err
+	}
+	fmt.Fprintln(w, "| URL | Status | Size | Content Type | Redirection | Depth | Source | Words | Lines | Title | Body Hash |")
+	_, err := fmt.Fprintln(w, "|-----|--------|------|--------------|-------------|-------|--------|-------|-------|-------|-----------|")
+	return err
+}
+
+// WriteResult 追加一个结果
+func (mw *MarkdownWriter) WriteResult(w io.Writer, result *Result) error {
+	if mw.diff {
+		fmt.Fprintf(w, "| %s ", escapeMarkdown(changeText(result.Change)))
+	}
+	_, err := fmt.Fprintf(w, "| %s | %d | %d | %s | %s | %d | %s | %d | %d | %s | %s |\n",
+		escapeMarkdown(result.URL), result.Status, result.Size, escapeMarkdown(result.ContentType),
+		escapeMarkdown(result.Redirect), result.Depth, escapeMarkdown(result.Source), result.Words, result.Lines, escapeMarkdown(result.Title), result.BodyHash)
+	return err
+}
+
+// End 写入报告结尾
+func (mw *MarkdownWriter) End(w io.Writer, info *Info) error {
+	_, err := fmt.Fprintf(w, "\nEnd time: %s\n", formatTime(info.EndTime))
+	return err
+}
+
+// changeMarker 变化类型的标记符号
+func changeMarker(change *Change) string {
+	switch change.Type {
+	case ChangeNew:
+		return "+"
+	case ChangeRemoved:
+		return "-"
+	default:
+		return "~"
+	}
+}
+
+// changeText 变化的描述，没有变化时为空
+func changeText(change *Change) string {
+	if change == nil {
+		return ""
+	}
+	return change.String()
+}
+
+// escapeMarkdown 转义表格单元格中的特殊字符
+func escapeMarkdown(s string) string {
+	return strings.NewReplacer("|", "\\|", "\n", " ").Replace(s)
+}
+
+// sortedKeys 按字母顺序获取映射的键
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,81 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+)
+
+// xmlReport XML报告的根元素
+type xmlReport struct {
+	XMLName  xml.Name    `xml:"hidirscan"`
+	Info     xmlInfo     `xml:"info"`
+	Results  []*Result   `xml:"results>result"`
+	Finished xmlFinished `xml:"finished"`
+}
+
+// xmlInfo 扫描信息
+type xmlInfo struct {
+	*Info
+	Options []xmlOption `xml:"options>option"`
+}
+
+// xmlOption 扫描选项
+type xmlOption struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+// xmlFinished 扫描结束信息
+type xmlFinished struct {
+	EndTime time.Time `xml:"end_time,attr"`
+}
+
+// XMLWriter XML格式
+//
+// 中断的报告补上 "</results></hidirscan>" 即可解析。
+type XMLWriter struct{}
+
+// Begin 写入报告开头
+func (xw *XMLWriter) Begin(w io.Writer, info *Info) error {
+	element := xmlInfo{Info: info}
+	for _, key := range sortedKeys(info.Options) {
+		element.Options = append(element.Options, xmlOption{Name: key, Value: info.Options[key]})
+	}
+
+	if _, err := io.WriteString(w, xml.Header+"<hidirscan>\n"); err != nil {
+		return err
+	}
+	if err := xw.encode(w, element, "info"); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "<results>\n")
+	return err
+}
+
+// WriteResult 追加一个结果
+func (xw *XMLWriter) WriteResult(w io.Writer, result *Result) error {
+	return xw.encode(w, result, "result")
+}
+
+// End 写入报告结尾
+func (xw *XMLWriter) End(w io.Writer, info *Info) error {
+	if _, err := io.WriteString(w, "</results>\n"); err != nil {
+		return err
+	}
+	if err := xw.encode(w, xmlFinished{EndTime: info.EndTime}, "finished"); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "</hidirscan>\n")
+	return err
+}
+
+// encode 将v编码为名为name的元素并换行
+func (xw *XMLWriter) encode(w io.Writer, v interface{}, name string) error {
+	if err := xml.NewEncoder(w).EncodeElement(v, xml.StartElement{Name: xml.Name{Local: name}}); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintln(w)
+	return err
+}
@@ -0,0 +1,62 @@
+package utils
+
+import (
+	"hash/fnv"
+	"math"
+)
+
+// BloomFilter 布隆过滤器，用于在有限内存内对大量字符串去重
+//
+// 存在一定的误判率：少量从未出现过的字符串可能被认为已经存在，
+// 但已经添加过的字符串一定会被识别出来。
+type BloomFilter struct {
+	bits   []uint64
+	size   uint64
+	hashes uint64
+}
+
+// NewBloomFilter 根据预计元素数量和误判率创建布隆过滤器
+func NewBloomFilter(capacity int, falsePositiveRate float64) *BloomFilter {
+	if capacity < 1 {
+		capacity = 1
+	}
+	if falsePositiveRate <= 0 || falsePositiveRate >= 1 {
+		falsePositiveRate = 0.0001
+	}
+
+	// m = -n*ln(p)/(ln2)^2, k = m/n*ln2
+	size := uint64(math.Ceil(-float64(capacity) * math.Log(falsePositiveRate) / (math.Ln2 * math.Ln2)))
+	if size < 64 {
+		size = 64
+	}
+	hashes := uint64(math.Round(float64(size) / float64(capacity) * math.Ln2))
+	if hashes < 1 {
+		hashes = 1
+	}
+
+	return &BloomFilter{
+		bits:   make([]uint64, (size+63)/64),
+		size:   size,
+		hashes: hashes,
+	}
+}
+
+// TestAndAdd 检查字符串是否已存在，并将其加入过滤器
+func (b *BloomFilter) TestAndAdd(s string) bool {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	sum := h.Sum64()
+	h1, h2 := sum&0xffffffff, sum>>32|1
+
+	exists := true
+	for i := uint64(0); i < b.hashes; i++ {
+		pos := (h1 + i*h2) % b.size
+		word, mask := pos/64, uint64(1)<<(pos%64)
+		if b.bits[word]&mask == 0 {
+			exists = false
+			b.bits[word] |= mask
+		}
+	}
+
+	return exists
+}
@@ -2,8 +2,10 @@ package utils
 
 import (
 	"fmt"
+	"math"
 	"math/rand"
 	"net"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -67,6 +69,33 @@ func HumanSize(bytes int64) string {
 	return fmt.Sprintf("%.1f%cB", float64(bytes)/float64(div), "KMGTPE"[exp])
 }
 
+// ParseHumanSize 将人类可读的大小解析为字节数，是HumanSize的逆操作，如 "0B"、"4KB"、"1.5MB"、"512"
+func ParseHumanSize(size string) (int64, error) {
+	value := strings.ToUpper(strings.TrimSpace(size))
+	value = strings.TrimSuffix(value, "B")
+
+	multiplier := int64(1)
+	if value != "" {
+		if exp := strings.IndexByte("KMGTPE", value[len(value)-1]); exp >= 0 {
+			for i := 0; i <= exp; i++ {
+				multiplier *= 1024
+			}
+			value = value[:len(value)-1]
+		}
+	}
+
+	number, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil || number < 0 || math.IsNaN(number) {
+		return 0, fmt.Errorf("invalid size %q", size)
+	}
+	// float64(math.MaxInt64)等于2^63，相等时转换同样会溢出
+	if number >= float64(math.MaxInt64)/float64(multiplier) {
+		return 0, fmt.Errorf("size %q is too large", size)
+	}
+
+	return int64(number * float64(multiplier)), nil
+}
+
 // LstripOnce 移除字符串开头的指定字符一次
 func LstripOnce(s, prefix string) string {
 	if strings.HasPrefix(s, prefix) {
@@ -0,0 +1,237 @@
+package utils
+
+import (
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// 参与LCS计算的最大单元数，超过后以唯一元素为锚点分段比较
+const maxDiffCells = 4000000
+
+var (
+	// 形如 csrf_token="..." 或 name="authenticity_token" value="..." 的令牌值
+	tokenValueRegex = regexp.MustCompile(`(?i)((?:csrf|xsrf|token|nonce|authenticity)[\w-]*["']?(?:\s+value)?\s*[=:]\s*["']?)[^"'\s&<>]+`)
+	// 较长的随机字符串
+	randomStringRegex = regexp.MustCompile(`[A-Za-z0-9+/_-]{20,}={0,2}`)
+	// 数字（包括时间戳、日期中的数字）
+	numberRegex = regexp.MustCompile(`\d+`)
+)
+
+// NormalizeContent 去除内容中的动态部分：反射的路径、令牌、随机字符串和数字
+func NormalizeContent(content string, reflected ...string) string {
+	for _, r := range reflected {
+		if r == "" {
+			continue
+		}
+		content = strings.ReplaceAll(content, r, "")
+		if escaped := url.PathEscape(r); escaped != r {
+			content = strings.ReplaceAll(content, escaped, "")
+		}
+	}
+
+	content = tokenValueRegex.ReplaceAllString(content, "$1")
+	content = randomStringRegex.ReplaceAllStringFunc(content, func(s string) string {
+		// 只去除同时包含字母和数字的字符串，避免误伤普通单词
+		if strings.ContainsAny(s, "0123456789") && strings.IndexFunc(s, isLetter) >= 0 {
+			return ""
+		}
+		return s
+	})
+	content = numberRegex.ReplaceAllString(content, "")
+
+	return content
+}
+
+// isLetter 检查字符是否为ASCII字母
+func isLetter(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+}
+
+// CommonTokens 获取两个序列中按顺序共同出现的元素
+func CommonTokens(a, b []string) []string {
+	// 公共前缀
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+
+	// 公共后缀
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	result := make([]string, 0, prefix+suffix)
+	result = append(result, a[:prefix]...)
+
+	middleA := a[prefix : len(a)-suffix]
+	middleB := b[prefix : len(b)-suffix]
+	result = append(result, commonMiddle(middleA, middleB)...)
+
+	result = append(result, a[len(a)-suffix:]...)
+
+	return result
+}
+
+// commonMiddle 获取去除首尾公共部分后的公共子序列
+//
+// 序列较短时计算精确的LCS；较长时以在两个序列中都只出现一次的元素为锚点（patience diff），
+// 在锚点之间分段递归比较；没有锚点时退化为按出现次数取交集，结果不一定保持b中的顺序。
+func commonMiddle(a, b []string) []string {
+	if len(a) == 0 || len(b) == 0 {
+		return nil
+	}
+	if len(a)*len(b) <= maxDiffCells {
+		return lcs(a, b)
+	}
+
+	anchors := uniqueAnchors(a, b)
+	if len(anchors) == 0 {
+		return commonMultiset(a, b)
+	}
+
+	var result []string
+	startA, startB := 0, 0
+	for _, anchor := range anchors {
+		result = append(result, CommonTokens(a[startA:anchor[0]], b[startB:anchor[1]])...)
+		result = append(result, a[anchor[0]])
+		startA, startB = anchor[0]+1, anchor[1]+1
+	}
+	result = append(result, CommonTokens(a[startA:], b[startB:])...)
+
+	return result
+}
+
+// uniqueAnchors 获取在两个序列中都只出现一次的元素的位置，取其中在两个序列中顺序一致的最长一组
+func uniqueAnchors(a, b []string) [][2]int {
+	countA := make(map[string]int, len(a))
+	for _, token := range a {
+		countA[token]++
+	}
+	positionB := make(map[string]int, len(b))
+	countB := make(map[string]int, len(b))
+	for i, token := range b {
+		countB[token]++
+		positionB[token] = i
+	}
+
+	var pairs [][2]int
+	for i, token := range a {
+		if countA[token] == 1 && countB[token] == 1 {
+			pairs = append(pairs, [2]int{i, positionB[token]})
+		}
+	}
+
+	// 按b中的位置求最长递增子序列，tails[k]为长度k+1的子序列末尾的下标
+	var tails []int
+	previous := make([]int, len(pairs))
+	for i, pair := range pairs {
+		k := sort.Search(len(tails), func(k int) bool { return pairs[tails[k]][1] >= pair[1] })
+		previous[i] = -1
+		if k > 0 {
+			previous[i] = tails[k-1]
+		}
+		if k == len(tails) {
+			tails = append(tails, i)
+		} else {
+			tails[k] = i
+		}
+	}
+
+	if len(tails) == 0 {
+		return nil
+	}
+	anchors := make([][2]int, len(tails))
+	for i, k := len(tails)-1, tails[len(tails)-1]; i >= 0; i, k = i-1, previous[k] {
+		anchors[i] = pairs[k]
+	}
+	return anchors
+}
+
+// commonMultiset 按出现次数获取两个序列的公共元素，保持a中的顺序
+func commonMultiset(a, b []string) []string {
+	counts := make(map[string]int, len(b))
+	for _, token := range b {
+		counts[token]++
+	}
+
+	var result []string
+	for _, token := range a {
+		if counts[token] > 0 {
+			counts[token]--
+			result = append(result, token)
+		}
+	}
+	return result
+}
+
+// lcs 计算最长公共子序列
+func lcs(a, b []string) []string {
+	if len(a) == 0 || len(b) == 0 {
+		return nil
+	}
+
+	// table[i][j] 表示 a[i:] 与 b[j:] 的最长公共子序列长度
+	width := len(b) + 1
+	table := make([]int32, (len(a)+1)*width)
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				table[i*width+j] = table[(i+1)*width+j+1] + 1
+			} else if table[(i+1)*width+j] >= table[i*width+j+1] {
+				table[i*width+j] = table[(i+1)*width+j]
+			} else {
+				table[i*width+j] = table[i*width+j+1]
+			}
+		}
+	}
+
+	var result []string
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		if a[i] == b[j] {
+			result = append(result, a[i])
+			i++
+			j++
+		} else if table[(i+1)*width+j] >= table[i*width+j+1] {
+			i++
+		} else {
+			j++
+		}
+	}
+
+	return result
+}
+
+// GenerateMatchingRegex 根据两个字符串生成同时匹配二者的正则表达式
+func GenerateMatchingRegex(s1, s2 string) string {
+	start := "^"
+	end := "$"
+
+	diverged := false
+	n := len(s1)
+	if len(s2) < n {
+		n = len(s2)
+	}
+	prefix := 0
+	for prefix < n && s1[prefix] == s2[prefix] {
+		prefix++
+	}
+	if prefix < len(s1) || prefix < len(s2) {
+		diverged = true
+	}
+	start += regexp.QuoteMeta(s1[:prefix])
+
+	if diverged {
+		start += ".*"
+		suffix := 0
+		for suffix < len(s1)-prefix && suffix < len(s2)-prefix && s1[len(s1)-1-suffix] == s2[len(s2)-1-suffix] {
+			suffix++
+		}
+		end = regexp.QuoteMeta(s1[len(s1)-suffix:]) + end
+	}
+
+	return start + end
+}
@@ -2,6 +2,7 @@ package utils
 
 import (
 	"bufio"
+	"bytes"
 	"os"
 	"path/filepath"
 	"strings"
@@ -59,6 +60,68 @@ func (f *File) GetLines() []string {
 	return lines
 }
 
+// CountLines 统计文件行数，只扫描换行符而不解析内容
+func (f *File) CountLines() int {
+	file, err := os.Open(f.path)
+	if err != nil {
+		return 0
+	}
+	defer file.Close()
+
+	count := 0
+	last := byte('\n')
+	buf := make([]byte, 256*1024)
+	for {
+		n, err := file.Read(buf)
+		if n > 0 {
+			count += bytes.Count(buf[:n], []byte{'\n'})
+			last = buf[n-1]
+		}
+		if err != nil {
+			break
+		}
+	}
+
+	// 最后一行没有换行符
+	if last != '\n' {
+		count++
+	}
+
+	return count
+}
+
+// LineReader 逐行读取文件，不会将整个文件载入内存
+type LineReader struct {
+	file    *os.File
+	scanner *bufio.Scanner
+}
+
+// NewLineReader 打开文件并创建LineReader
+func (f *File) NewLineReader() (*LineReader, error) {
+	file, err := os.Open(f.path)
+	if err != nil {
+		return nil, err
+	}
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	return &LineReader{file: file, scanner: scanner}, nil
+}
+
+// Next 读取下一行，文件结束时返回false
+func (r *LineReader) Next() (string, bool) {
+	if !r.scanner.Scan() {
+		return "", false
+	}
+	return r.scanner.Text(), true
+}
+
+// Close 关闭文件
+func (r *LineReader) Close() error {
+	return r.file.Close()
+}
+
 // Read 读取文件内容
 func (f *File) Read() string {
 	content, err := os.ReadFile(f.path)
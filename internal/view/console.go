@@ -0,0 +1,133 @@
+package view
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+	"unicode/utf8"
+)
+
+// 终端默认宽度
+const defaultTerminalWidth = 80
+
+// Console 控制台输出，可在终端最后一行显示状态行，输出的内容不会与状态行交错
+type Console struct {
+	mutex   sync.Mutex
+	out     io.Writer
+	status  string // 当前状态行
+	drawn   bool   // 状态行是否显示在屏幕上
+	enabled bool   // 是否显示状态行
+	tty     bool   // 输出是否为终端
+	hidden  bool   // 暂时隐藏状态行，如显示菜单时
+	width   int
+}
+
+// NewConsole 创建新的Console实例，status为true且输出为终端时显示状态行
+func NewConsole(file *os.File, status bool) *Console {
+	tty := IsTerminal(file)
+	return &Console{
+		out:     file,
+		enabled: status && tty,
+		tty:     tty,
+		width:   terminalWidth(),
+	}
+}
+
+// Terminal 检查输出是否为终端
+func (c *Console) Terminal() bool {
+	return c.tty
+}
+
+// Printf 格式化输出，输出前清除状态行，输出后重新绘制
+func (c *Console) Printf(format string, args ...interface{}) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.clear()
+	fmt.Fprintf(c.out, format, args...)
+	c.draw()
+}
+
+// Println 输出一行
+func (c *Console) Println(args ...interface{}) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.clear()
+	fmt.Fprintln(c.out, args...)
+	c.draw()
+}
+
+// SetStatus 更新状态行
+func (c *Console) SetStatus(status string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.status = status
+	c.draw()
+}
+
+// HideStatus 隐藏状态行，直到调用ShowStatus
+func (c *Console) HideStatus() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.clear()
+	c.hidden = true
+}
+
+// ShowStatus 重新显示状态行
+func (c *Console) ShowStatus() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.hidden = false
+	c.draw()
+}
+
+// ClearStatus 清除状态行
+func (c *Console) ClearStatus() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.clear()
+	c.status = ""
+}
+
+// clear 从屏幕上清除状态行
+func (c *Console) clear() {
+	if c.drawn {
+		fmt.Fprint(c.out, "\r\033[K")
+		c.drawn = false
+	}
+}
+
+// draw 绘制状态行，超出终端宽度的部分被截断以免换行
+func (c *Console) draw() {
+	if !c.enabled || c.hidden || c.status == "" {
+		return
+	}
+
+	status := c.status
+	if utf8.RuneCountInString(status) >= c.width {
+		status = string([]rune(status)[:c.width-1])
+	}
+	fmt.Fprint(c.out, "\r\033[K"+status)
+	c.drawn = true
+}
+
+// IsTerminal 检查文件是否为终端
+func IsTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// terminalWidth 获取终端宽度，无法获取时使用默认值
+func terminalWidth() int {
+	if width, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && width > 1 {
+		return width
+	}
+	return defaultTerminalWidth
+}
@@ -0,0 +1,75 @@
+package view
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestConsole(t *testing.T) {
+	// 测试用例1：输出内容前清除状态行，输出后重新绘制
+	t.Run("StatusRedrawn", func(t *testing.T) {
+		var out bytes.Buffer
+		console := &Console{out: &out, enabled: true, width: 80}
+
+		console.SetStatus("50%")
+		console.Println("[200] /admin")
+
+		expected := "\r\033[K50%" + "\r\033[K" + "[200] /admin\n" + "\r\033[K50%"
+		if out.String() != expected {
+			t.Errorf("Expected %q, got %q", expected, out.String())
+		}
+	})
+
+	// 测试用例2：状态行超出终端宽度时截断
+	t.Run("StatusTruncated", func(t *testing.T) {
+		var out bytes.Buffer
+		console := &Console{out: &out, enabled: true, width: 10}
+
+		console.SetStatus(strings.Repeat("x", 20))
+
+		if got := strings.TrimPrefix(out.String(), "\r\033[K"); got != strings.Repeat("x", 9) {
+			t.Errorf("Expected status truncated to 9 characters, got %q", got)
+		}
+	})
+
+	// 测试用例3：隐藏状态行时不绘制
+	t.Run("HiddenStatus", func(t *testing.T) {
+		var out bytes.Buffer
+		console := &Console{out: &out, enabled: true, width: 80}
+
+		console.HideStatus()
+		console.SetStatus("50%")
+		console.Println("menu")
+		if out.String() != "menu\n" {
+			t.Errorf("Expected no status while hidden, got %q", out.String())
+		}
+
+		console.ShowStatus()
+		if !strings.HasSuffix(out.String(), "\r\033[K50%") {
+			t.Errorf("Expected status redrawn after ShowStatus, got %q", out.String())
+		}
+	})
+
+	// 测试用例4：输出不是终端时不显示状态行
+	t.Run("NotTerminal", func(t *testing.T) {
+		file, err := os.CreateTemp(t.TempDir(), "console")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer file.Close()
+
+		console := NewConsole(file, true)
+		console.SetStatus("50%")
+		console.Println("[200] /admin")
+
+		data, err := os.ReadFile(file.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != "[200] /admin\n" {
+			t.Errorf("Expected only the output line, got %q", string(data))
+		}
+	})
+}
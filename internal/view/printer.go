@@ -0,0 +1,154 @@
+package view
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"HiDir/internal/connection"
+	"HiDir/internal/parse"
+	"HiDir/internal/utils"
+)
+
+// ANSI颜色
+const (
+	colorReset  = "\033[0m"
+	colorRed    = "\033[31m"
+	colorGreen  = "\033[32m"
+	colorYellow = "\033[33m"
+	colorBlue   = "\033[34m"
+	colorCyan   = "\033[36m"
+	colorGray   = "\033[90m"
+)
+
+// Printer 格式化输出扫描结果和消息
+//
+// 安静模式下只向标准输出打印匹配结果的完整URL，便于通过管道交给其他工具处理，
+// 其他消息中只有错误会输出到标准错误。
+type Printer struct {
+	console *Console
+	errOut  io.Writer
+	color   bool
+	fullURL bool
+	history bool
+	quiet   bool
+}
+
+// NewPrinter 创建新的Printer实例
+//
+// 设置了NO_COLOR环境变量或输出不是终端时不使用颜色。虚拟主机模式下路径相同，总是显示完整URL。
+func NewPrinter(console *Console, opts *parse.Options) *Printer {
+	return &Printer{
+		console: console,
+		errOut:  os.Stderr,
+		color:   opts.Color && os.Getenv("NO_COLOR") == "" && console.Terminal(),
+		fullURL: opts.FullURL || opts.Vhost,
+		history: opts.RedirectsHistory,
+		quiet:   opts.Quiet,
+	}
+}
+
+// Result 输出匹配的响应
+func (p *Printer) Result(response *connection.Response) {
+	if p.quiet {
+		p.console.Println(response.FullPath)
+		return
+	}
+
+	path := "/" + strings.TrimPrefix(response.Path, "/")
+	if p.fullURL {
+		path = response.FullPath
+	}
+
+	line := fmt.Sprintf("[%d] %7s %6dW %5dL  %s", response.Status, utils.HumanSize(response.Length), response.Words, response.Lines, path)
+	if response.Redirect != "" {
+		line += "  ->  " + response.Redirect
+	}
+	if response.RedirectErr != nil {
+		line += fmt.Sprintf(" (%s)", response.RedirectErr)
+	}
+	if response.Payload != "" {
+		line += fmt.Sprintf("  {%s}", response.Payload)
+	}
+	if response.Title != "" {
+		line += fmt.Sprintf("  [%s]", response.Title)
+	}
+	line = p.colorize(statusColor(response.Status), line)
+
+	// 显示完整的重定向链
+	if p.history && len(response.History) > 0 {
+		var chain []string
+		for _, hop := range response.History {
+			chain = append(chain, fmt.Sprintf("%s [%d]", hop.URL, hop.Status))
+		}
+		// 最终响应不是重定向时显示其状态码，否则最后一跳未被请求
+		last := response.Redirect
+		if response.Status < 300 || response.Status >= 400 {
+			last += fmt.Sprintf(" [%d]", response.Status)
+		}
+		chain = append(chain, last)
+		line += "\n" + p.colorize(colorGray, "    Redirects: "+strings.Join(chain, " -> "))
+	}
+
+	p.console.Println(line)
+}
+
+// Filtered 输出被过滤的响应及原因
+func (p *Printer) Filtered(response *connection.Response, reason string) {
+	if p.quiet {
+		return
+	}
+	p.console.Println(p.colorize(colorGray, fmt.Sprintf("[-] [%d] %s %s", response.Status, response.FullPath, reason)))
+}
+
+// Info 输出普通消息
+func (p *Printer) Info(format string, args ...interface{}) {
+	if p.quiet {
+		return
+	}
+	p.console.Printf(format, args...)
+}
+
+// Warning 输出警告消息
+func (p *Printer) Warning(format string, args ...interface{}) {
+	if p.quiet {
+		return
+	}
+	p.console.Println(p.colorize(colorYellow, strings.TrimSuffix(fmt.Sprintf(format, args...), "\n")))
+}
+
+// Error 输出错误消息，安静模式下输出到标准错误
+func (p *Printer) Error(format string, args ...interface{}) {
+	message := strings.TrimSuffix(fmt.Sprintf(format, args...), "\n")
+	if p.quiet {
+		fmt.Fprintln(p.errOut, message)
+		return
+	}
+	p.console.Println(p.colorize(colorRed, message))
+}
+
+// colorize 为文本添加颜色
+func (p *Printer) colorize(color, text string) string {
+	if !p.color || color == "" {
+		return text
+	}
+	return color + text + colorReset
+}
+
+// statusColor 根据状态码类别选择颜色
+func statusColor(status int) string {
+	switch {
+	case status >= 200 && status < 300:
+		return colorGreen
+	case status >= 300 && status < 400:
+		return colorCyan
+	case status == 401 || status == 403:
+		return colorBlue
+	case status >= 400 && status < 500:
+		return colorYellow
+	case status >= 500:
+		return colorRed
+	}
+	return ""
+}
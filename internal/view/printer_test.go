@@ -0,0 +1,113 @@
+package view
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"HiDir/internal/connection"
+)
+
+func TestPrinter(t *testing.T) {
+	response := &connection.Response{
+		Status:   301,
+		Length:   0,
+		Path:     "admin",
+		FullPath: "http://example.com/admin",
+		Redirect: "http://example.com/admin/",
+		History:  []connection.RedirectHop{{URL: "http://example.com/admin", Status: 301}},
+	}
+
+	newPrinter := func(printer Printer) (*Printer, *bytes.Buffer, *bytes.Buffer) {
+		var out, errOut bytes.Buffer
+		printer.console = &Console{out: &out, width: 80}
+		printer.errOut = &errOut
+		return &printer, &out, &errOut
+	}
+
+	// 测试用例1：默认输出相对路径和重定向目标
+	t.Run("RelativePath", func(t *testing.T) {
+		printer, out, _ := newPrinter(Printer{})
+		printer.Result(response)
+
+		expected := "[301]      0B      0W     0L  /admin  ->  http://example.com/admin/\n"
+		if out.String() != expected {
+			t.Errorf("Expected %q, got %q", expected, out.String())
+		}
+	})
+
+	// 测试用例2：完整URL和重定向历史
+	t.Run("FullURLAndHistory", func(t *testing.T) {
+		printer, out, _ := newPrinter(Printer{fullURL: true, history: true})
+		printer.Result(response)
+
+		lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+		if len(lines) != 2 {
+			t.Fatalf("Expected 2 lines, got %q", out.String())
+		}
+		if !strings.Contains(lines[0], "  http://example.com/admin  ->") {
+			t.Errorf("Expected full URL, got %q", lines[0])
+		}
+		if lines[1] != "    Redirects: http://example.com/admin [301] -> http://example.com/admin/" {
+			t.Errorf("Unexpected redirect history %q", lines[1])
+		}
+	})
+
+	// 测试用例3：跟随重定向后显示每一跳的状态码，停止跟随时显示原因
+	t.Run("FollowedHistory", func(t *testing.T) {
+		printer, out, _ := newPrinter(Printer{history: true})
+		printer.Result(&connection.Response{
+			Status:   200,
+			Path:     "admin",
+			Redirect: "http://example.com/admin/",
+			History:  []connection.RedirectHop{{URL: "http://example.com/admin", Status: 301}},
+		})
+		printer.Result(&connection.Response{
+			Status:      302,
+			Path:        "sso",
+			Redirect:    "http://login.example.org/",
+			History:     []connection.RedirectHop{{URL: "http://example.com/sso", Status: 302}},
+			RedirectErr: connection.ErrCrossHostRedirect,
+		})
+
+		lines := strings.Split(out.String(), "\n")
+		if lines[1] != "    Redirects: http://example.com/admin [301] -> http://example.com/admin/ [200]" {
+			t.Errorf("Unexpected redirect history %q", lines[1])
+		}
+		if !strings.HasSuffix(lines[2], "->  http://login.example.org/ (cross-host redirect)") {
+			t.Errorf("Expected cross-host redirect to be reported, got %q", lines[2])
+		}
+	})
+
+	// 测试用例4：按状态码类别着色
+	t.Run("Color", func(t *testing.T) {
+		printer, out, _ := newPrinter(Printer{color: true})
+		printer.Result(&connection.Response{Status: 200, Path: "index.php"})
+		printer.Result(&connection.Response{Status: 500, Path: "error.php"})
+
+		lines := strings.Split(out.String(), "\n")
+		if !strings.HasPrefix(lines[0], colorGreen) || !strings.HasSuffix(lines[0], colorReset) {
+			t.Errorf("Expected green line for 200, got %q", lines[0])
+		}
+		if !strings.HasPrefix(lines[1], colorRed) {
+			t.Errorf("Expected red line for 500, got %q", lines[1])
+		}
+	})
+
+	// 测试用例5：安静模式只输出URL，错误输出到标准错误
+	t.Run("Quiet", func(t *testing.T) {
+		printer, out, errOut := newPrinter(Printer{quiet: true, color: true})
+		printer.Info("Task Completed\n")
+		printer.Warning("Skipped the target")
+		printer.Filtered(response, "status")
+		printer.Result(response)
+		printer.Error("Error: %s", "disk full")
+
+		if out.String() != "http://example.com/admin\n" {
+			t.Errorf("Expected only the URL, got %q", out.String())
+		}
+		if errOut.String() != "Error: disk full\n" {
+			t.Errorf("Expected error on stderr, got %q", errOut.String())
+		}
+	})
+}
@@ -0,0 +1,102 @@
+// Package filter 定义响应过滤器接口和过滤器链，库的使用者可以实现Filter接口加入自己的过滤规则
+package filter
+
+import (
+	"fmt"
+
+	"HiDir/internal/connection"
+)
+
+// Response 过滤器判定的HTTP响应
+type Response = connection.Response
+
+// Verdict 过滤器对响应的判定结果
+type Verdict struct {
+	Rejected bool   // 是否丢弃该响应
+	Filter   string // 做出判定的过滤器名称
+	Reason   string // 丢弃原因
+}
+
+// Accept 接受响应
+func Accept() Verdict {
+	return Verdict{}
+}
+
+// Reject 丢弃响应并说明原因
+func Reject(format string, args ...interface{}) Verdict {
+	return Verdict{Rejected: true, Reason: fmt.Sprintf(format, args...)}
+}
+
+// String 返回判定结果的描述
+func (v Verdict) String() string {
+	if !v.Rejected {
+		return "accepted"
+	}
+	return fmt.Sprintf("rejected by %s: %s", v.Filter, v.Reason)
+}
+
+// Filter 响应过滤器，决定一个响应是否作为结果保留
+//
+// 扫描线程会并发调用Match，实现需要保证并发安全。
+type Filter interface {
+	// Name 过滤器名称，出现在丢弃原因中
+	Name() string
+	// Match 判定响应是否保留
+	Match(response *Response) Verdict
+}
+
+// Chain 依次执行多个过滤器，遇到第一个丢弃判定即停止
+type Chain struct {
+	filters []Filter
+}
+
+// NewChain 创建新的Chain实例
+func NewChain(filters ...Filter) *Chain {
+	return &Chain{filters: append([]Filter{}, filters...)}
+}
+
+// Add 添加过滤器，返回自身以便链式调用
+func (c *Chain) Add(filters ...Filter) *Chain {
+	c.filters = append(c.filters, filters...)
+	return c
+}
+
+// Filters 获取所有过滤器
+func (c *Chain) Filters() []Filter {
+	return c.filters
+}
+
+// Match 依次执行过滤器，返回第一个丢弃判定，全部通过时接受
+func (c *Chain) Match(response *Response) Verdict {
+	for _, filter := range c.filters {
+		verdict := filter.Match(response)
+		if verdict.Rejected {
+			if verdict.Filter == "" {
+				verdict.Filter = filter.Name()
+			}
+			return verdict
+		}
+	}
+	return Accept()
+}
+
+// funcFilter 由函数实现的过滤器
+type funcFilter struct {
+	name  string
+	match func(*Response) Verdict
+}
+
+// NewFunc 用函数创建过滤器
+func NewFunc(name string, match func(*Response) Verdict) Filter {
+	return &funcFilter{name: name, match: match}
+}
+
+// Name 过滤器名称
+func (f *funcFilter) Name() string {
+	return f.name
+}
+
+// Match 判定响应是否保留
+func (f *funcFilter) Match(response *Response) Verdict {
+	return f.match(response)
+}
@@ -0,0 +1,56 @@
+package filter
+
+import (
+	"strings"
+	"testing"
+)
+
+// statusFilter 测试用的自定义过滤器，丢弃指定状态码的响应
+type statusFilter int
+
+func (s statusFilter) Name() string {
+	return "status"
+}
+
+func (s statusFilter) Match(response *Response) Verdict {
+	if response.Status == int(s) {
+		return Reject("status %d", response.Status)
+	}
+	return Accept()
+}
+
+func TestChain(t *testing.T) {
+	debug := NewFunc("no-debug", func(response *Response) Verdict {
+		if strings.Contains(response.Content, "debug") {
+			return Verdict{Rejected: true, Filter: "debug-page", Reason: "debug page"}
+		}
+		return Accept()
+	})
+	chain := NewChain(statusFilter(404)).Add(debug)
+
+	tests := []struct {
+		name     string
+		response *Response
+		filter   string
+	}{
+		{name: "Accepted", response: &Response{Status: 200, Content: "ok"}},
+		{name: "FilterName", response: &Response{Status: 404, Content: "debug"}, filter: "status"},
+		{name: "VerdictFilter", response: &Response{Status: 200, Content: "debug"}, filter: "debug-page"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verdict := chain.Match(tt.response)
+			if verdict.Rejected != (tt.filter != "") {
+				t.Fatalf("Expected rejected=%v, got %s", tt.filter != "", verdict)
+			}
+			if verdict.Filter != tt.filter {
+				t.Errorf("Expected filter %q, got %q", tt.filter, verdict.Filter)
+			}
+		})
+	}
+
+	if len(chain.Filters()) != 2 {
+		t.Errorf("Expected 2 filters, got %d", len(chain.Filters()))
+	}
+}